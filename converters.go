@@ -3,50 +3,17 @@ package gonfig
 import (
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// convertToInt converts the value to int like the sized integer getters do, see convertToIntSize
 func convertToInt(val interface{}) (int, error) {
-	var i int // your final value
-	var err error
-	switch t := val.(type) {
-	case int:
-		i = t
-	case int8:
-		i = int(t) // standardizes across systems
-	case int16:
-		i = int(t) // standardizes across systems
-	case int32:
-		i = int(t) // standardizes across systems
-	case int64:
-		i = int(t) // standardizes across systems
-	case bool:
-		if t {
-			i = 1
-		} else {
-			i = 0
-		}
-	case float32:
-		i = int(t) // standardizes across systems
-	case float64:
-		i = int(t) // standardizes across systems
-	case uint:
-		i = int(t) // standardizes across systems
-	case uint8:
-		i = int(t) // standardizes across systems
-	case uint16:
-		i = int(t) // standardizes across systems
-	case uint32:
-		i = int(t) // standardizes across systems
-	case uint64:
-		i = int(t) // standardizes across systems
-	case string:
-		i, err = strconv.Atoi(t)
-	default:
-		i = 0
-		err = errors.New("Unknown type")
-	}
-	return i, err
+	i, err := convertToIntSize(val, 0)
+	return int(i), err
 }
 
 func convertToString(val interface{}) string {
@@ -88,12 +55,15 @@ func convertToFloat(val interface{}) (float64, error) {
 	case uint64:
 		f = float64(t) // standardizes across systems
 	case string:
-		f, err = strconv.ParseFloat(t, 64)
+		f, err = strconv.ParseFloat(strings.TrimSpace(t), 64)
 	default:
 		f = 0
-		err = errors.New("Unknown type")
+		err = errUnknownType
 	}
-	return f, err
+	if err != nil {
+		return f, &ConversionError{Value: val, Type: "float64", Err: err}
+	}
+	return f, nil
 }
 
 func convertToBool(val interface{}) (bool, error) {
@@ -107,7 +77,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case int8:
 		switch t {
@@ -116,7 +86,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case int16:
 		switch t {
@@ -125,7 +95,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case int32:
 		switch t {
@@ -134,7 +104,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case int64:
 		switch t {
@@ -143,7 +113,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case bool:
 		b = t
@@ -154,7 +124,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case float64:
 		switch t {
@@ -163,7 +133,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case uint:
 		switch t {
@@ -172,7 +142,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case uint8:
 		switch t {
@@ -181,7 +151,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case uint16:
 		switch t {
@@ -190,7 +160,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case uint32:
 		switch t {
@@ -199,7 +169,7 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case uint64:
 		switch t {
@@ -208,20 +178,404 @@ func convertToBool(val interface{}) (bool, error) {
 		case 1:
 			b = true
 		default:
-			err = errors.New("Unknown value")
+			err = errUnknownValue
 		}
 	case string:
-		switch t {
-		case "0":
-			b = false
-		case "1":
-			b = true
-		default:
-			err = errors.New("Unknown value")
+		var found bool
+		if b, found = boolStrings[strings.ToLower(strings.TrimSpace(t))]; !found {
+			err = errUnknownValue
 		}
 	default:
 		b = false
-		err = errors.New("Unknown type")
+		err = errUnknownType
+	}
+	if err != nil {
+		return b, &ConversionError{Value: val, Type: "bool", Err: err}
+	}
+	return b, nil
+}
+
+// boolStrings are the strings convertToBool accepts, in lower case
+var boolStrings = map[string]bool{
+	"1":     true,
+	"0":     false,
+	"true":  true,
+	"false": false,
+	"yes":   true,
+	"no":    false,
+	"on":    true,
+	"off":   false,
+	"y":     true,
+	"n":     false,
+	"t":     true,
+	"f":     false,
+}
+
+// convertToStrictBool converts like convertToBool, but accepts only "true", "false", "1" and "0" as strings
+func convertToStrictBool(val interface{}) (bool, error) {
+	if s, ok := val.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "true", "false", "1", "0":
+		default:
+			return false, &ConversionError{Value: val, Type: "bool", Err: errUnknownValue}
+		}
+	}
+	return convertToBool(val)
+}
+
+// convertToDuration converts Go duration strings like "1m30s", the extended units "d" and "w" like "2d12h", and numbers.
+// Numbers, including the strings of numbers, are multiplied by the given unit.
+func convertToDuration(val interface{}, unit time.Duration) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch t := val.(type) {
+	case time.Duration:
+		d = t
+	case bool:
+		err = errUnknownType
+	case string:
+		s := strings.TrimSpace(t)
+		if f, ferr := strconv.ParseFloat(s, 64); ferr == nil {
+			d, err = durationOf(f, unit)
+		} else {
+			d, err = parseDuration(s)
+		}
+	default:
+		var f float64
+		if f, err = convertToFloat(val); err == nil {
+			d, err = durationOf(f, unit)
+		} else {
+			err = errUnknownType
+		}
+	}
+	if err != nil {
+		return 0, &ConversionError{Value: val, Type: "time.Duration", Err: err}
+	}
+	return d, nil
+}
+
+// durationOf multiplies a number by a unit, and returns errOverflow if the result doesn't fit in a time.Duration
+func durationOf(f float64, unit time.Duration) (time.Duration, error) {
+	d := f * float64(unit)
+	if math.IsNaN(d) || d >= math.MaxInt64 || d < math.MinInt64 {
+		return 0, errOverflow
+	}
+	return time.Duration(d), nil
+}
+
+// durationUnits are the units that are supported in addition to the ones of time.ParseDuration
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseDuration parses a duration string like time.ParseDuration, but also accepts days ("d") and weeks ("w")
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil || !strings.ContainsAny(s, "dw") {
+		return d, err
+	}
+	sign, rest := time.Duration(1), s
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	} else {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+	var total time.Duration
+	for rest != "" {
+		end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end <= 0 {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		number := rest[:end]
+		rest = rest[end:]
+		end = strings.IndexFunc(rest, func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if end < 0 {
+			end = len(rest)
+		}
+		unit := rest[:end]
+		rest = rest[end:]
+		if size, found := durationUnits[unit]; found {
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("time: invalid duration %q", s)
+			}
+			part, err := durationOf(f, size)
+			if err != nil || total > math.MaxInt64-part {
+				return 0, errOverflow
+			}
+			total += part
+			continue
+		}
+		part, err := time.ParseDuration(number + unit)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		if total > math.MaxInt64-part {
+			return 0, errOverflow
+		}
+		total += part
+	}
+	return sign * total, nil
+}
+
+// timeLayouts are the layouts that are tried to parse time strings, in order
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// tomlLocalZones are the names of the zones the TOML decoder sets for the local dates and times, which have no time zone
+var tomlLocalZones = map[string]bool{
+	"datetime-local": true,
+	"date-local":     true,
+	"time-local":     true,
+}
+
+// convertToTime converts time.Time values, which the TOML decoder produces for dates, and strings in RFC 3339 or its date-only and local forms.
+// Times without a time zone, including the local dates and times of TOML, are in UTC.
+func convertToTime(val interface{}) (time.Time, error) {
+	var err error
+	switch t := val.(type) {
+	case time.Time:
+		if tomlLocalZones[t.Location().String()] {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), nil
+		}
+		return t, nil
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range timeLayouts {
+			var parsed time.Time
+			if parsed, err = time.Parse(layout, s); err == nil {
+				return parsed, nil
+			}
+		}
+		err = errUnknownValue
+	default:
+		err = errUnknownType
+	}
+	return time.Time{}, &ConversionError{Value: val, Type: "time.Time", Err: err}
+}
+
+// byteSizeUnits are the multipliers of the byte size units in lower case. Units without "i" are SI (powers of 1000), units with "i" are IEC (powers of 1024)
+var byteSizeUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"p":   1e15,
+	"pb":  1e15,
+	"e":   1e18,
+	"eb":  1e18,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+	"ei":  1 << 60,
+	"eib": 1 << 60,
+}
+
+// convertToByteSize converts sizes like "512KB", "1.5GiB" or "10M" and numbers of bytes to uint64.
+// Units are case-insensitive, SI units like "MB" are powers of 1000 and IEC units like "MiB" are powers of 1024.
+func convertToByteSize(val interface{}) (uint64, error) {
+	var size float64
+	var err error
+	switch t := val.(type) {
+	case bool:
+		err = errUnknownType
+	case uint64:
+		return t, nil
+	case string:
+		s := strings.TrimSpace(t)
+		end := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end < 0 {
+			end = len(s)
+		}
+		multiplier, found := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[end:]))]
+		if !found || end == 0 {
+			err = errUnknownValue
+			break
+		}
+		if n, uerr := strconv.ParseUint(s[:end], 10, 64); uerr == nil {
+			if n > math.MaxUint64/multiplier {
+				err = errOverflow
+				break
+			}
+			return n * multiplier, nil
+		}
+		if size, err = strconv.ParseFloat(s[:end], 64); err == nil {
+			size *= float64(multiplier)
+		}
+	default:
+		if size, err = convertToFloat(val); err != nil {
+			err = errUnknownType
+		}
+	}
+	if err == nil && (size < 0 || size >= math.MaxUint64) {
+		err = errOverflow
+	}
+	if err != nil {
+		return 0, &ConversionError{Value: val, Type: "uint64", Err: err}
+	}
+	return uint64(size), nil
+}
+
+// convertToRatio converts percentages like "75%" and numbers like "0.75" or 0.75 to float64
+func convertToRatio(val interface{}) (float64, error) {
+	s, ok := val.(string)
+	if !ok || !strings.HasSuffix(strings.TrimSpace(s), "%") {
+		return convertToFloat(val)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return 0, &ConversionError{Value: val, Type: "float64", Err: err}
+	}
+	return f / 100, nil
+}
+
+// convertToIntSize converts the value to an integer that fits in the given number of bits, or in int if bitSize is 0.
+// It rejects the floats that are not integral and the values that overflow the type.
+// Strings can have 0x, 0o and 0b prefixes and _ digit separators like Go literals, and a leading 0 doesn't make them octal.
+func convertToIntSize(val interface{}, bitSize int) (int64, error) {
+	typeName := "int"
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	} else {
+		typeName = fmt.Sprintf("int%d", bitSize)
+	}
+	var i int64
+	var err error
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			err = errOverflow
+		}
+		i = int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		i, err = floatToInt64(rv.Float())
+	case reflect.Bool:
+		if rv.Bool() {
+			i = 1
+		}
+	case reflect.String:
+		s := integerLiteral(rv.String())
+		if i, err = strconv.ParseInt(s, 0, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+			if f, ferr := strconv.ParseFloat(s, 64); ferr == nil {
+				i, err = floatToInt64(f)
+			}
+		}
+	default:
+		err = errUnknownType
+	}
+	if errors.Is(err, strconv.ErrRange) || (err == nil && (i < -1<<(bitSize-1) || i > 1<<(bitSize-1)-1)) {
+		err = errOverflow
+	}
+	if err != nil {
+		return 0, &ConversionError{Value: val, Type: typeName, Err: err}
+	}
+	return i, nil
+}
+
+// convertToUintSize converts the value to an unsigned integer that fits in the given number of bits, or in uint if bitSize is 0.
+// Negative values overflow, see convertToIntSize for the other rules.
+func convertToUintSize(val interface{}, bitSize int) (uint64, error) {
+	typeName := "uint"
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	} else {
+		typeName = fmt.Sprintf("uint%d", bitSize)
+	}
+	var u uint64
+	var err error
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			err = errOverflow
+		}
+		u = uint64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u = rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		u, err = floatToUint64(rv.Float())
+	case reflect.Bool:
+		if rv.Bool() {
+			u = 1
+		}
+	case reflect.String:
+		s := integerLiteral(rv.String())
+		if u, err = strconv.ParseUint(s, 0, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+			if i, ierr := strconv.ParseInt(s, 0, 64); ierr == nil && i < 0 {
+				err = errOverflow
+			} else if f, ferr := strconv.ParseFloat(s, 64); ferr == nil {
+				u, err = floatToUint64(f)
+			}
+		}
+	default:
+		err = errUnknownType
+	}
+	if errors.Is(err, strconv.ErrRange) || (err == nil && bitSize < 64 && u > 1<<bitSize-1) {
+		err = errOverflow
+	}
+	if err != nil {
+		return 0, &ConversionError{Value: val, Type: typeName, Err: err}
+	}
+	return u, nil
+}
+
+// integerLiteral trims the spaces and the leading zeros of a decimal number, so that strconv doesn't parse it as octal
+func integerLiteral(s string) string {
+	s = strings.TrimSpace(s)
+	sign, digits := "", s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if len(digits) < 2 || digits[0] != '0' || strings.ContainsAny(digits[1:2], "xXoObB._") {
+		return s
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" || digits[0] == '.' {
+		digits = "0" + digits
+	}
+	return sign + digits
+}
+
+// floatToInt64 converts an integral float to int64
+func floatToInt64(f float64) (int64, error) {
+	if math.Trunc(f) != f {
+		return 0, errNotIntegral
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, errOverflow
+	}
+	return int64(f), nil
+}
+
+// floatToUint64 converts a non-negative integral float to uint64
+func floatToUint64(f float64) (uint64, error) {
+	if math.Trunc(f) != f {
+		return 0, errNotIntegral
+	}
+	if f < 0 || f >= math.MaxUint64 {
+		return 0, errOverflow
 	}
-	return b, err
+	return uint64(f), nil
 }
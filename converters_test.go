@@ -1,7 +1,10 @@
 package gonfig
 
 import (
+	"errors"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,7 +103,7 @@ func Test_convertToInt(t *testing.T) {
 	unknown["test"] = "test"
 	val, err = convertToInt(unknown)
 	assert.Equal(t, 0, val)
-	assert.EqualError(t, err, "Unknown type")
+	assert.ErrorIs(t, err, errUnknownType)
 }
 
 func Test_convertToString(t *testing.T) {
@@ -278,7 +281,7 @@ func Test_convertToFloat(t *testing.T) {
 	unknown["test"] = "test"
 	val, err = convertToFloat(unknown)
 	assert.Equal(t, 0.0, val)
-	assert.EqualError(t, err, "Unknown type")
+	assert.ErrorIs(t, err, errUnknownType)
 }
 
 func Test_convertToBool(t *testing.T) {
@@ -295,7 +298,7 @@ func Test_convertToBool(t *testing.T) {
 	intVal = 2
 	val, err = convertToBool(intVal)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// string
 	var strVal string
 	strVal = "0"
@@ -309,7 +312,7 @@ func Test_convertToBool(t *testing.T) {
 	strVal = "12"
 	val, err = convertToBool(strVal)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// float32
 	var fl32Val float32
 	fl32Val = 0.0
@@ -323,7 +326,7 @@ func Test_convertToBool(t *testing.T) {
 	fl32Val = 21.0
 	val, err = convertToBool(fl32Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// float64
 	var fl64Val float64
 	fl64Val = 0.0
@@ -337,7 +340,7 @@ func Test_convertToBool(t *testing.T) {
 	fl64Val = 21.0
 	val, err = convertToBool(fl64Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// bool
 	var boolVal bool
 	boolVal = true
@@ -361,7 +364,7 @@ func Test_convertToBool(t *testing.T) {
 	int8Val = 8
 	val, err = convertToBool(int8Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// int16
 	var int16Val int16
 	int16Val = 0
@@ -375,7 +378,7 @@ func Test_convertToBool(t *testing.T) {
 	int16Val = 16
 	val, err = convertToBool(int16Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// int32
 	var int32Val int32
 	int32Val = 0
@@ -389,7 +392,7 @@ func Test_convertToBool(t *testing.T) {
 	int32Val = 32
 	val, err = convertToBool(int32Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// int64
 	var int64Val int64
 	int64Val = 0
@@ -403,7 +406,7 @@ func Test_convertToBool(t *testing.T) {
 	int64Val = 64
 	val, err = convertToBool(int64Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// uint
 	var uintVal uint
 	uintVal = 0
@@ -417,7 +420,7 @@ func Test_convertToBool(t *testing.T) {
 	uintVal = 11
 	val, err = convertToBool(uintVal)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// uint8
 	var uint8Val uint8
 	uint8Val = 0
@@ -431,7 +434,7 @@ func Test_convertToBool(t *testing.T) {
 	uint8Val = 8
 	val, err = convertToBool(uint8Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// uint16
 	var uint16Val uint16
 	uint16Val = 0
@@ -445,7 +448,7 @@ func Test_convertToBool(t *testing.T) {
 	uint16Val = 16
 	val, err = convertToBool(uint16Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// uint32
 	var uint32Val uint32
 	uint32Val = 0
@@ -459,7 +462,7 @@ func Test_convertToBool(t *testing.T) {
 	uint32Val = 32
 	val, err = convertToBool(uint32Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// uint64
 	var uint64Val uint64
 	uint64Val = 0
@@ -473,11 +476,273 @@ func Test_convertToBool(t *testing.T) {
 	uint64Val = 64
 	val, err = convertToBool(uint64Val)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown value")
+	assert.ErrorIs(t, err, errUnknownValue)
 	// unknown
 	var unknown = make(map[string]string)
 	unknown["test"] = "test"
 	val, err = convertToBool(unknown)
 	assert.Equal(t, false, val)
-	assert.EqualError(t, err, "Unknown type")
+	assert.ErrorIs(t, err, errUnknownType)
+}
+
+func Test_convertToDuration(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected time.Duration
+	}{
+		{"1m30s", 90 * time.Second},
+		{"2d", 48 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"1w2d3h4m", 9*24*time.Hour + 3*time.Hour + 4*time.Minute},
+		{"1.5d", 36 * time.Hour},
+		{"-2d12h", -60 * time.Hour},
+		{" 250ms ", 250 * time.Millisecond},
+		{"30", 30 * time.Second},
+		{"0.5", 500 * time.Millisecond},
+		{30, 30 * time.Second},
+		{int64(2), 2 * time.Second},
+		{1.5, 1500 * time.Millisecond},
+		{5 * time.Minute, 5 * time.Minute},
+	}
+	for _, test := range tests {
+		val, err := convertToDuration(test.val, time.Second)
+		assert.Nil(t, err, test.val)
+		assert.Equal(t, test.expected, val, test.val)
+	}
+	val, err := convertToDuration(250, time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, 250*time.Millisecond, val)
+	for _, invalid := range []interface{}{"soon", "2x", "d", "1d2", true, []interface{}{1}} {
+		_, err = convertToDuration(invalid, time.Second)
+		var convErr *ConversionError
+		assert.True(t, errors.As(err, &convErr), invalid)
+		assert.Equal(t, "time.Duration", convErr.Type)
+	}
+	_, err = convertToDuration(true, time.Second)
+	assert.ErrorIs(t, err, errUnknownType)
+	for _, overflowing := range []interface{}{"1e30", "300000w", "-300000w", "200000w200000w", 1e20, "2562047h1w"} {
+		_, err = convertToDuration(overflowing, time.Second)
+		var convErr *ConversionError
+		assert.True(t, errors.As(err, &convErr), overflowing)
+		assert.ErrorIs(t, err, errOverflow, overflowing)
+	}
+}
+
+func Test_convertToTime(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected time.Time
+	}{
+		{"2022-03-04T05:06:07Z", time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"2022-03-04T05:06:07.5+02:00", time.Date(2022, 3, 4, 5, 6, 7, 500000000, time.FixedZone("", 2*60*60))},
+		{"2022-03-04T05:06:07", time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"2022-03-04 05:06:07", time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"2022-03-04", time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2022, 3, 4, 0, 0, 0, 0, time.Local), time.Date(2022, 3, 4, 0, 0, 0, 0, time.Local)},
+	}
+	for _, test := range tests {
+		val, err := convertToTime(test.val)
+		assert.Nil(t, err, test.val)
+		assert.True(t, test.expected.Equal(val), test.val)
+	}
+	_, err := convertToTime("yesterday")
+	assert.ErrorIs(t, err, errUnknownValue)
+	_, err = convertToTime(12)
+	assert.ErrorIs(t, err, errUnknownType)
+}
+
+func Test_convertToByteSize(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected uint64
+	}{
+		{"512KB", 512000},
+		{"512kb", 512000},
+		{"512KiB", 512 * 1024},
+		{"1.5GiB", 1536 * 1024 * 1024},
+		{"10M", 10000000},
+		{"10Mi", 10 * 1024 * 1024},
+		{"1 TB", 1000000000000},
+		{"100", 100},
+		{"100B", 100},
+		{"18446744073709551615", math.MaxUint64},
+		{4096, 4096},
+		{int64(1), 1},
+		{2048.0, 2048},
+		{uint64(math.MaxUint64), math.MaxUint64},
+	}
+	for _, test := range tests {
+		val, err := convertToByteSize(test.val)
+		assert.Nil(t, err, test.val)
+		assert.Equal(t, test.expected, val, test.val)
+	}
+	for _, invalid := range []interface{}{"KB", "12XB", "large", "1.2.3MB"} {
+		_, err := convertToByteSize(invalid)
+		var convErr *ConversionError
+		assert.True(t, errors.As(err, &convErr), invalid)
+		assert.Equal(t, "uint64", convErr.Type)
+	}
+	_, err := convertToByteSize("20EiB")
+	assert.ErrorIs(t, err, errOverflow)
+	_, err = convertToByteSize(-1)
+	assert.ErrorIs(t, err, errOverflow)
+	_, err = convertToByteSize(true)
+	assert.ErrorIs(t, err, errUnknownType)
+}
+
+func Test_convertToRatio(t *testing.T) {
+	val, err := convertToRatio("75%")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.75, val)
+	val, err = convertToRatio(" 12.5 % ")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.125, val)
+	val, err = convertToRatio("0.75")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.75, val)
+	val, err = convertToRatio(0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, val)
+	_, err = convertToRatio("half%")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "float64", convErr.Type)
+	_, err = convertToRatio([]interface{}{})
+	assert.ErrorIs(t, err, errUnknownType)
+}
+
+func Test_convertToBool_Strings(t *testing.T) {
+	for _, s := range []string{"true", "TRUE", "True", "yes", "Yes", "on", "ON", "y", "Y", "t", "T", "1", " true "} {
+		val, err := convertToBool(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, true, val, s)
+	}
+	for _, s := range []string{"false", "FALSE", "no", "No", "off", "OFF", "n", "N", "f", "F", "0"} {
+		val, err := convertToBool(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, false, val, s)
+	}
+	for _, s := range []string{"", "maybe", "yess", "2"} {
+		_, err := convertToBool(s)
+		assert.ErrorIs(t, err, errUnknownValue, s)
+	}
+}
+
+func Test_convertToStrictBool(t *testing.T) {
+	val, err := convertToStrictBool("TRUE")
+	assert.Nil(t, err)
+	assert.Equal(t, true, val)
+	val, err = convertToStrictBool("0")
+	assert.Nil(t, err)
+	assert.Equal(t, false, val)
+	val, err = convertToStrictBool(1)
+	assert.Nil(t, err)
+	assert.Equal(t, true, val)
+	for _, s := range []string{"yes", "off", "y", "t"} {
+		_, err = convertToStrictBool(s)
+		assert.ErrorIs(t, err, errUnknownValue, s)
+	}
+}
+
+func Test_convertToIntSize(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		bitSize  int
+		expected int64
+	}{
+		{42, 64, 42},
+		{int8(-8), 64, -8},
+		{uint64(math.MaxInt64), 64, math.MaxInt64},
+		{3.0, 64, 3},
+		{true, 64, 1},
+		{"0x1F", 64, 31},
+		{"0o17", 64, 15},
+		{"0b101", 64, 5},
+		{"1_000_000", 64, 1000000},
+		{"-0x10", 64, -16},
+		{"010", 64, 10},
+		{" -007 ", 64, -7},
+		{"1e3", 64, 1000},
+		{"9223372036854775807", 64, math.MaxInt64},
+		{"-9223372036854775808", 64, math.MinInt64},
+		{"2147483647", 32, math.MaxInt32},
+		{-128, 8, -128},
+		{"12", 0, 12},
+	}
+	for _, test := range tests {
+		val, err := convertToIntSize(test.val, test.bitSize)
+		assert.Nil(t, err, test.val)
+		assert.Equal(t, test.expected, val, test.val)
+	}
+	overflows := []struct {
+		val     interface{}
+		bitSize int
+	}{
+		{uint64(math.MaxUint64), 64},
+		{"9223372036854775808", 64},
+		{1e19, 64},
+		{"2147483648", 32},
+		{int64(math.MinInt32) - 1, 32},
+		{128, 8},
+	}
+	for _, test := range overflows {
+		_, err := convertToIntSize(test.val, test.bitSize)
+		assert.ErrorIs(t, err, errOverflow, test.val)
+	}
+	for _, val := range []interface{}{3.9, "3.9", float32(-0.5)} {
+		_, err := convertToIntSize(val, 64)
+		assert.ErrorIs(t, err, errNotIntegral, val)
+	}
+	_, err := convertToIntSize("0x1G", 64)
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.EqualError(t, err, "Cannot convert 0x1G (string) to int64: strconv.ParseInt: parsing \"0x1G\": invalid syntax")
+	_, err = convertToIntSize([]interface{}{1}, 32)
+	assert.ErrorIs(t, err, errUnknownType)
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "int32", convErr.Type)
+}
+
+func Test_convertToUintSize(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		bitSize  int
+		expected uint64
+	}{
+		{42, 64, 42},
+		{uint64(math.MaxUint64), 64, math.MaxUint64},
+		{"18446744073709551615", 64, math.MaxUint64},
+		{"0xFFFF_FFFF", 32, math.MaxUint32},
+		{"0b1111_1111", 8, 255},
+		{"0", 64, 0},
+		{"-0", 64, 0},
+		{2.0, 16, 2},
+		{false, 64, 0},
+	}
+	for _, test := range tests {
+		val, err := convertToUintSize(test.val, test.bitSize)
+		assert.Nil(t, err, test.val)
+		assert.Equal(t, test.expected, val, test.val)
+	}
+	overflows := []struct {
+		val     interface{}
+		bitSize int
+	}{
+		{-1, 64},
+		{"-5", 64},
+		{-2.0, 64},
+		{"18446744073709551616", 64},
+		{"4294967296", 32},
+		{256, 8},
+	}
+	for _, test := range overflows {
+		_, err := convertToUintSize(test.val, test.bitSize)
+		assert.ErrorIs(t, err, errOverflow, test.val)
+	}
+	_, err := convertToUintSize("1.5", 64)
+	assert.ErrorIs(t, err, errNotIntegral)
+	_, err = convertToUintSize("many", 0)
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "uint", convErr.Type)
 }
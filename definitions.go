@@ -1,21 +1,51 @@
 package gonfig
 
+import (
+	"io"
+	"io/fs"
+)
+
 // SourceType describes the source type of configuration to be read
 type SourceType string
 
 const (
 	// SourceTypeEnv is used for reading from environment settings
 	SourceTypeEnv SourceType = "env"
+	// SourceTypeDotenv is used for reading from .env files. Keys are looked up the same way as SourceTypeEnv
+	SourceTypeDotenv = "dotenv"
+	// SourceTypeFlags is used for reading from --key=value command-line flags
+	SourceTypeFlags = "flags"
 	// SourceTypeJSON is used for reading from JSON formatted files
 	SourceTypeJSON = "json"
 	// SourceTypeYaml is used for reading from yaml formatted files
 	SourceTypeYaml = "yaml"
+	// SourceTypeTOML is used for reading from TOML formatted files
+	SourceTypeTOML = "toml"
+	// SourceTypeINI is used for reading from INI formatted files. Sections are nested under their names
+	SourceTypeINI = "ini"
+	// SourceTypeProperties is used for reading from Java .properties files
+	SourceTypeProperties = "properties"
+	// SourceTypeHCL is used for reading from HCL formatted files. Blocks are nested under their types and labels
+	SourceTypeHCL = "hcl"
 )
 
 // ConfigSource is the type that is used to describe various config sources.
 type ConfigSource struct {
 	// Type is the type SourceType of the ConfigSource
 	Type SourceType
-	// FilePath is the absolute path to the file if the Type is SourceType.JSON or SourceType.Yaml
+	// FilePath is the absolute path to the file if the Type is a file based SourceType like SourceType.JSON or SourceType.Yaml.
+	// If FS is set, it's the path of the file inside FS. If Data or Reader is set, it's only used to name the source
 	FilePath string
+	// FS is the file system to read FilePath from instead of the disk, e.g. an embed.FS
+	FS fs.FS
+	// Data is the content of a file based source. It's used instead of reading FilePath if it's not nil
+	Data []byte
+	// Reader is read to get the content of a file based source if Data is nil. It's read only once, when the source is added
+	Reader io.Reader
+	// Args is the list of command-line arguments if the Type is SourceTypeFlags. os.Args[1:] is used if it's nil
+	Args []string
+	// Separator separates the items of arrays like "[a,b]" and maps like "{k1=v1,k2=v2}" in the values of SourceTypeEnv and SourceTypeDotenv. It's "," if it's empty
+	Separator string
+	// Prefix is prepended to the variable names of SourceTypeEnv and SourceTypeDotenv, e.g. the key "servers[0].host" is read from APP_SERVERS_0_HOST if it's "APP"
+	Prefix string
 }
\ No newline at end of file
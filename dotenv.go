@@ -0,0 +1,177 @@
+package gonfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dotenvSource is a Source that reads a .env file and looks keys up the same way as the env source
+type dotenvSource struct {
+	fileSource
+	format envFormat
+}
+
+func newDotenvSource(s ConfigSource) Source {
+	return &dotenvSource{fileSource: fileSource{config: s, decode: decodeDotenv}, format: newEnvFormat(s)}
+}
+
+func (s *dotenvSource) Lookup(key string) (interface{}, bool) {
+	return s.format.lookup(key, envMap(s.items))
+}
+
+// Settings returns the variables of the file keyed by the paths they're looked up with, see envFormat.settings
+func (s *dotenvSource) Settings() map[string]interface{} {
+	return s.format.settings(envMap(s.items))
+}
+
+func (s *dotenvSource) assemble(key string) (interface{}, bool) {
+	return s.format.assemble(s.format.prefix+envKey(key), envMap(s.items))
+}
+
+func decodeDotenv(data []byte) (map[string]interface{}, error) {
+	values, err := parseDotenv(string(data), os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	output := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		output[key] = val
+	}
+	return output, nil
+}
+
+// parseDotenv parses the contents of a .env file.
+// Lines may start with "export", values may be single quoted (literal), double quoted (escapes and expansion)
+// or unquoted (expansion, inline comments and "\" line continuations). Quoted values can span multiple lines.
+// ${NAME}, ${NAME:-default} and $NAME are expanded from the earlier lines first, then from the environment.
+func parseDotenv(data string, lookup func(string) (string, bool)) (map[string]string, error) {
+	values := make(map[string]string)
+	resolve := func(name string) (string, bool) {
+		if val, found := values[name]; found {
+			return val, true
+		}
+		return lookup(name)
+	}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t") // Trailing spaces are trimmed outside the quotes only
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, &ParseError{Format: "dotenv", Line: lineNo, Msg: "missing '=' after the key"}
+		}
+		key := strings.TrimSpace(line[:eq])
+		if key == "" || strings.ContainsAny(key, " \t\"'") {
+			return nil, &ParseError{Format: "dotenv", Line: lineNo, Msg: fmt.Sprintf("invalid key %q", key)}
+		}
+		rest := strings.TrimLeft(line[eq+1:], " \t")
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			quote := rest[0]
+			body := rest[1:]
+			end := closingQuote(body, quote)
+			for end < 0 { // Quoted values may span multiple lines
+				i++
+				if i >= len(lines) {
+					return nil, &ParseError{Format: "dotenv", Line: lineNo, Msg: "unterminated quoted value"}
+				}
+				body += "\n" + lines[i]
+				end = closingQuote(body, quote)
+			}
+			if trailing := strings.TrimSpace(body[end+1:]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return nil, &ParseError{Format: "dotenv", Line: i + 1, Msg: "unexpected characters after the quoted value"}
+			}
+			value = body[:end]
+			if quote == '"' {
+				value = expandDotenv(value, true, resolve)
+			}
+		} else {
+			rest = strings.TrimRight(rest, " \t")
+			for strings.HasSuffix(rest, "\\") && i+1 < len(lines) { // Line continuation
+				i++
+				rest = rest[:len(rest)-1] + strings.TrimSpace(lines[i])
+			}
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			value = expandDotenv(strings.TrimSpace(rest), false, resolve)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// closingQuote returns the index of the quote that closes a quoted value, skipping escaped double quotes
+func closingQuote(body string, quote byte) int {
+	for i := 0; i < len(body); i++ {
+		if quote == '"' && body[i] == '\\' {
+			i++
+			continue
+		}
+		if body[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDotenv expands the variables in a value, and processes the backslash escapes if escapes is true
+func expandDotenv(value string, escapes bool, resolve func(string) (string, bool)) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case escapes && ch == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			default: // \\, \", \$ and unknown escapes are written as the escaped character
+				sb.WriteByte(value[i])
+			}
+		case ch == '$' && i+1 < len(value) && value[i+1] == '{':
+			end := strings.Index(value[i:], "}")
+			if end < 0 {
+				sb.WriteString(value[i:])
+				return sb.String()
+			}
+			name := value[i+2 : i+end]
+			defaultValue := ""
+			if sep := strings.Index(name, ":-"); sep >= 0 {
+				name, defaultValue = name[:sep], name[sep+2:]
+			}
+			if val, found := resolve(name); found && val != "" {
+				sb.WriteString(val)
+			} else {
+				sb.WriteString(defaultValue)
+			}
+			i += end
+		case ch == '$' && i+1 < len(value) && isEnvNameChar(value[i+1]):
+			end := i + 1
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			val, _ := resolve(value[i+1 : end])
+			sb.WriteString(val)
+			i = end - 1
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+func isEnvNameChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
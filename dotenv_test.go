@@ -0,0 +1,122 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseDotenv(t *testing.T) {
+	payload := `# A comment
+export APP_NAME=gonfig
+PLAIN = value with spaces   # inline comment
+SINGLE='literal ${APP_NAME} \n'
+DOUBLE="hello ${APP_NAME}\nsecond \"line\" \$HOME"
+BARE=$APP_NAME-suffix
+DEFAULTED=${MISSING:-fallback}
+FROM_ENV=${GONFIG_DOTENV_TEST}
+MULTI="first
+second"
+CONTINUED=one \
+two
+EMPTY=
+`
+	lookup := func(name string) (string, bool) {
+		if name == "GONFIG_DOTENV_TEST" {
+			return "from process", true
+		}
+		return "", false
+	}
+	values, err := parseDotenv(payload, lookup)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"APP_NAME":  "gonfig",
+		"PLAIN":     "value with spaces",
+		"SINGLE":    "literal ${APP_NAME} \\n",
+		"DOUBLE":    "hello gonfig\nsecond \"line\" $HOME",
+		"BARE":      "gonfig-suffix",
+		"DEFAULTED": "fallback",
+		"FROM_ENV":  "from process",
+		"MULTI":     "first\nsecond",
+		"CONTINUED": "one two",
+		"EMPTY":     "",
+	}, values)
+	// Spaces are kept inside the quotes, and trimmed outside them
+	values, err = parseDotenv("MULTI=\"first  \nsecond \"  \nSINGLE='a  '  \nCONTINUED=one \\  \ntwo  ", lookup)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"MULTI": "first  \nsecond ", "SINGLE": "a  ", "CONTINUED": "one two"}, values)
+}
+
+func Test_parseDotenv_Errors(t *testing.T) {
+	_, err := parseDotenv("KEY1=value1\nKEY2", os.LookupEnv)
+	assert.EqualError(t, err, "dotenv: line 2: missing '=' after the key")
+	_, err = parseDotenv("KEY1=\"value1\nKEY2=value2", os.LookupEnv)
+	assert.EqualError(t, err, "dotenv: line 1: unterminated quoted value")
+	_, err = parseDotenv("KEY1='value1' value2", os.LookupEnv)
+	assert.EqualError(t, err, "dotenv: line 1: unexpected characters after the quoted value")
+	_, err = parseDotenv("MY KEY=value1", os.LookupEnv)
+	assert.EqualError(t, err, "dotenv: line 1: invalid key \"MY KEY\"")
+}
+
+func Test_AddConfigSource_Dotenv(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"localhost\",\"port\":5432}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	mockFile("DATABASE_HOST=db.local\nDATABASE_PORT=6543\nports=[80,443]", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeDotenv,
+		FilePath: ".env",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	assert.Equal(t, false, c.HasError)
+	assert.Equal(t, "dotenv:.env", c.sources[1].provider.Name())
+	val, err := c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "db.local", val)
+	arr, err := c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, arr)
+	// The real environment overrides the .env file because it's added later
+	os.Setenv("DATABASE_PORT", "7654")
+	defer os.Unsetenv("DATABASE_PORT")
+	port, err := c.GetInt("database.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 7654, port)
+}
+
+func Test_DotenvSource_Settings(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeDotenv,
+		Data: []byte("DATABASE_HOST=b\nSERVERS_1_PORT=81\ntags=[x,y]\nname=svc\nPORT=80"),
+	})
+	assert.Equal(t, map[string]interface{}{
+		"database": map[string]interface{}{"host": "b"},
+		"servers":  []interface{}{nil, map[string]interface{}{"port": "81"}},
+		"tags":     []interface{}{"x", "y"},
+		"name":     "svc",
+		"PORT":     "80",
+	}, c.AllSettings())
+	assert.Equal(t, map[string]string{
+		"PORT":            "dotenv:bytes",
+		"database.host":   "dotenv:bytes",
+		"name":            "dotenv:bytes",
+		"servers[1].port": "dotenv:bytes",
+		"tags[0]":         "dotenv:bytes",
+		"tags[1]":         "dotenv:bytes",
+	}, c.Provenance())
+	c = c.AddConfigSource(ConfigSource{
+		Type:   SourceTypeDotenv,
+		Prefix: "APP",
+		Data:   []byte("APP_LOG_LEVEL=debug\nOTHER=x"),
+	})
+	assert.Equal(t, "debug", c.AllSettings()["log"].(map[string]interface{})["level"])
+	_, found := c.AllSettings()["OTHER"]
+	assert.Equal(t, false, found)
+}
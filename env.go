@@ -0,0 +1,447 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envSource is a Source that reads the environment variables of the process
+type envSource struct {
+	format envFormat
+	vars   osEnv
+}
+
+func newEnvSource(s ConfigSource) Source {
+	return &envSource{format: newEnvFormat(s)}
+}
+
+func (s *envSource) Name() string {
+	if s.format.prefix != "" {
+		return string(SourceTypeEnv) + ":" + strings.TrimSuffix(s.format.prefix, "_")
+	}
+	return string(SourceTypeEnv)
+}
+
+// Load takes a snapshot of the variable names, which are scanned to assemble the indexed variables
+func (s *envSource) Load() error {
+	s.vars = osEnv{variables: environNames()}
+	return nil
+}
+
+func (s *envSource) Lookup(key string) (interface{}, bool) {
+	return s.format.lookup(key, s.vars)
+}
+
+func (s *envSource) assemble(key string) (interface{}, bool) {
+	return s.format.assemble(s.format.prefix+envKey(key), s.vars)
+}
+
+// assembler is implemented by the sources that can assemble the arrays and maps of a key from variables like SERVERS_0_HOST.
+// Without a prefix, the variables are assembled only for the keys that are arrays or maps in the lower priority sources,
+// so that unrelated variables like JAVA_HOME don't turn a key like "java" into a map.
+type assembler interface {
+	assemble(key string) (interface{}, bool)
+}
+
+// composite returns true if the value is an array or a map
+func composite(val interface{}) bool {
+	if _, ok := toMap(val); ok {
+		return true
+	}
+	_, ok := toArray(val)
+	return ok
+}
+
+// lookupComposite looks up a key that's an array or a map in a lower priority source, so the variables of an assembler are assembled too
+func lookupComposite(source Source, key string) (interface{}, bool) {
+	if val, found := source.Lookup(key); found {
+		return val, true
+	}
+	if a, ok := source.(assembler); ok {
+		return a.assemble(key)
+	}
+	return nil, false
+}
+
+// envVars are the variables the env and dotenv sources read
+type envVars interface {
+	lookup(name string) (string, bool)
+	names() []string
+}
+
+// osEnv are the environment variables of the process. Their values are read when they're looked up,
+// and their names are a snapshot taken when the env source is loaded
+type osEnv struct {
+	variables []string
+}
+
+func (osEnv) lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+func (e osEnv) names() []string {
+	return e.variables
+}
+
+// environNames returns the names of the environment variables of the process
+func environNames() []string {
+	environ := os.Environ()
+	names := make([]string, 0, len(environ))
+	for _, variable := range environ {
+		if end := strings.Index(variable, "="); end > 0 {
+			names = append(names, variable[:end])
+		}
+	}
+	return names
+}
+
+// envMap are the variables read from a .env file
+type envMap map[string]interface{}
+
+func (m envMap) lookup(name string) (string, bool) {
+	val, found := m[name]
+	if !found {
+		return "", false
+	}
+	return convertToString(val), true
+}
+
+func (m envMap) names() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// envFormat defines how the keys are mapped to variable names, and how the values of the variables are parsed
+type envFormat struct {
+	// prefix is prepended to the variable names. It ends with "_" if it's not empty
+	prefix string
+	// separator separates the items of arrays and maps
+	separator string
+}
+
+func newEnvFormat(s ConfigSource) envFormat {
+	format := envFormat{separator: s.Separator}
+	if format.separator == "" {
+		format.separator = ","
+	}
+	if s.Prefix != "" {
+		format.prefix = strings.TrimSuffix(s.Prefix, "_") + "_"
+	}
+	return format
+}
+
+// lookup finds the value of the key in the variables.
+// Without a prefix the key is looked up as it is first, then the nested keys by their conventional variable names, e.g. SERVERS_0_HOST for "servers[0].host".
+// If there's no such variable and there's a prefix, the indexed variables under the name like APP_SERVERS_0_HOST and APP_SERVERS_1_HOST
+// for "servers" are assembled into arrays and maps, see assembler for the sources without a prefix.
+// Then the variables of the parent keys are looked into, e.g. SERVERS='[{"host":"a"}]' for "servers[0].host" or DATABASE='{host=a,port=5432}' for "database.host".
+// Only the values in brackets and braces are arrays and maps, see parseValue.
+func (f envFormat) lookup(key string, vars envVars) (interface{}, bool) {
+	if val, found := f.lookupName(key, vars, false); found {
+		return f.parseValue(val), true
+	}
+	if f.prefix != "" {
+		if val, found := f.assemble(f.prefix+envKey(key), vars); found {
+			return val, true
+		}
+	}
+	ancestors := keyAncestors(key)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		val, found := f.lookupName(ancestors[i][0], vars, true)
+		if !found {
+			continue
+		}
+		if val, found := lookupPath(f.parseValue(val), ancestors[i][1]); found {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// lookupName returns the raw value of the variable of the key.
+// Without a prefix, only the nested keys like "database.host" are mapped to conventional names, so that a top-level key like "user"
+// isn't read from an unrelated variable like USER. The parents of nested keys are always mapped, as only an array or a map value
+// can have the nested key, e.g. DATABASE for "database.host".
+func (f envFormat) lookupName(key string, vars envVars, parent bool) (string, bool) {
+	if f.prefix == "" {
+		if val, found := vars.lookup(key); found || (!parent && !strings.ContainsAny(key, ".[")) {
+			return val, found
+		}
+	}
+	name := f.prefix + envKey(key)
+	if name == key {
+		return "", false
+	}
+	return vars.lookup(name)
+}
+
+// settings returns the values of the variables as nested maps, keyed by the paths they're looked up with, e.g. "database.host" for DATABASE_HOST.
+// A name is mapped back to a dotted path whose conventional name it is, so a variable like MAX_CONNS is listed as "max.conns" even if it's
+// looked up as "max_conns" too. Without a prefix, the other names are listed as they are, and with a prefix, the variables without it are skipped.
+func (f envFormat) settings(vars envVars) map[string]interface{} {
+	names := vars.names()
+	sort.Strings(names)
+	settings := make(map[string]interface{})
+	for _, name := range names {
+		key, ok := f.envPath(name)
+		if !ok {
+			continue
+		}
+		val, _ := vars.lookup(name)
+		setPath(settings, key, f.parseValue(val))
+	}
+	return settings
+}
+
+// envPath returns the key whose variable name is the given name
+func (f envFormat) envPath(name string) (string, bool) {
+	if !strings.HasPrefix(name, f.prefix) || len(name) == len(f.prefix) {
+		return "", false
+	}
+	var key string
+	for _, word := range strings.Split(strings.ToLower(name[len(f.prefix):]), "_") {
+		if i, err := strconv.Atoi(word); err == nil && i >= 0 && i < maxArrayIndex && key != "" {
+			key = fmt.Sprintf("%s[%d]", key, i)
+		} else {
+			key = joinKey(key, word)
+		}
+	}
+	if f.prefix+envKey(key) == name && (f.prefix != "" || strings.ContainsAny(key, ".[")) {
+		return key, true
+	}
+	if f.prefix == "" {
+		return name, true
+	}
+	return "", false
+}
+
+// parseValue parses the arrays and the maps in the value of a variable.
+// Values in brackets are parsed as JSON arrays, or as items separated by the separator that can be quoted, e.g. [a,"b,c",' d'].
+// Values in braces are parsed as JSON objects, or as key=value pairs separated by the separator, e.g. {k1=v1,k2=v2}.
+// JSON values are returned as []interface{} and map[string]interface{}, the other arrays are returned as []string.
+func (f envFormat) parseValue(val string) interface{} {
+	trimmed := strings.TrimSpace(val)
+	switch {
+	case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+		var arr []interface{}
+		if err := json.Unmarshal([]byte(trimmed), &arr); err == nil {
+			return arr
+		}
+		return splitList(trimmed[1:len(trimmed)-1], f.separator)
+	case strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}"):
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &m); err == nil {
+			return m
+		}
+		if m, ok := parseKeyValues(trimmed[1:len(trimmed)-1], f.separator); ok {
+			return m
+		}
+	}
+	return val
+}
+
+// assemble builds the arrays and maps of the variables whose names start with the given name and "_".
+// Numeric parts of the names are array indices and the other parts are map keys in lower case, e.g. SERVERS_0_HOST and SERVERS_0_MAX_CONNS
+// for "servers" become [{"host": ..., "max_conns": ...}].
+func (f envFormat) assemble(name string, vars envVars) (interface{}, bool) {
+	prefix := name + "_"
+	var tree map[string]interface{}
+	for _, variable := range vars.names() {
+		if !strings.HasPrefix(variable, prefix) || len(variable) == len(prefix) {
+			continue
+		}
+		val, _ := vars.lookup(variable)
+		if tree == nil {
+			tree = make(map[string]interface{})
+		}
+		node := tree
+		segments := envSegments(variable[len(prefix):])
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				if _, found := node[segment]; found {
+					node = nil
+					break
+				}
+				child = make(map[string]interface{})
+				node[segment] = child
+			}
+			node = child
+		}
+		if node != nil {
+			if _, found := node[segments[len(segments)-1]]; !found {
+				node[segments[len(segments)-1]] = f.parseValue(val)
+			}
+		}
+	}
+	if tree == nil {
+		return nil, false
+	}
+	return indexedArrays(tree), true
+}
+
+// envSegments splits the rest of a variable name into its numeric parts and the underscore separated words between them in lower case.
+// "0_MAX_CONNS" is split as "0" and "max_conns".
+func envSegments(name string) []string {
+	var segments []string
+	var words []string
+	for _, word := range strings.Split(name, "_") {
+		if _, err := strconv.Atoi(word); err == nil {
+			if len(words) > 0 {
+				segments = append(segments, strings.ToLower(strings.Join(words, "_")))
+				words = nil
+			}
+			segments = append(segments, word)
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) > 0 {
+		segments = append(segments, strings.ToLower(strings.Join(words, "_")))
+	}
+	return segments
+}
+
+// indexedArrays converts the maps whose keys are all indices to arrays, recursively
+func indexedArrays(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	size := 0
+	for key, item := range m {
+		m[key] = indexedArrays(item)
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= maxArrayIndex {
+			size = -1
+		} else if size >= 0 && i >= size {
+			size = i + 1
+		}
+	}
+	if size < 0 {
+		return m
+	}
+	arr := make([]interface{}, size)
+	for key, item := range m {
+		i, _ := strconv.Atoi(key)
+		arr[i] = item
+	}
+	return arr
+}
+
+// keyAncestors returns the keys that contain the given key with the rest of the key inside them, from the outermost to the innermost.
+// "servers[0].host" has the ancestors "servers" with "[0].host", and "servers[0]" with "host".
+func keyAncestors(key string) [][2]string {
+	var ancestors [][2]string
+	prefix, rest := "", key
+	for rest != "" {
+		bracket := rest[0] == '['
+		head, next := splitKeyHead(rest)
+		if bracket {
+			prefix = fmt.Sprintf("%s[%s]", prefix, head)
+		} else {
+			prefix = joinKey(prefix, head)
+		}
+		if next == "" {
+			break
+		}
+		ancestors = append(ancestors, [2]string{prefix, next})
+		rest = next
+	}
+	return ancestors
+}
+
+// splitList splits the items separated by the separator. Items can be double quoted with "\" escapes, or single quoted,
+// to contain the separator, quotes or spaces around them. A quote is escaped by doubling it as in CSV.
+func splitList(s string, separator string) []string {
+	items := make([]string, 0)
+	for {
+		trimmed := strings.TrimLeft(s, " \t")
+		if trimmed != "" && (trimmed[0] == '"' || trimmed[0] == '\'') {
+			if item, rest, ok := unquoteItem(trimmed); ok {
+				rest = strings.TrimLeft(rest, " \t")
+				if rest == "" {
+					return append(items, item)
+				}
+				if strings.HasPrefix(rest, separator) {
+					items = append(items, item)
+					s = rest[len(separator):]
+					continue
+				}
+			}
+		}
+		end := strings.Index(s, separator)
+		if end < 0 {
+			return append(items, s)
+		}
+		items = append(items, s[:end])
+		s = s[end+len(separator):]
+	}
+}
+
+// unquoteItem reads a quoted item from the start of s, and returns the unquoted item and the rest of s
+func unquoteItem(s string) (string, string, bool) {
+	quote := s[0]
+	var item strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			item.WriteByte(s[i])
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+			item.WriteByte(quote)
+		case s[i] == quote:
+			return item.String(), s[i+1:], true
+		default:
+			item.WriteByte(s[i])
+		}
+	}
+	return "", s, false
+}
+
+// parseKeyValues parses key=value pairs separated by the separator, e.g. "k1=v1,k2=v2". Values can be quoted like the items of splitList,
+// e.g. k1="a,b". Returns false if any of the pairs has no key.
+func parseKeyValues(s string, separator string) (map[string]interface{}, bool) {
+	m := make(map[string]interface{})
+	for strings.TrimSpace(s) != "" {
+		end := strings.Index(s, "=")
+		if next := strings.Index(s, separator); end < 0 || (next >= 0 && next < end) {
+			if next >= 0 && strings.TrimSpace(s[:next]) == "" {
+				s = s[next+len(separator):]
+				continue
+			}
+			return nil, false
+		}
+		key := strings.TrimSpace(s[:end])
+		if key == "" {
+			return nil, false
+		}
+		s = strings.TrimLeft(s[end+1:], " \t")
+		val := ""
+		if s != "" && (s[0] == '"' || s[0] == '\'') {
+			if unquoted, rest, ok := unquoteItem(s); ok {
+				rest = strings.TrimLeft(rest, " \t")
+				if rest == "" || strings.HasPrefix(rest, separator) {
+					m[key] = unquoted
+					s = strings.TrimPrefix(rest, separator)
+					continue
+				}
+			}
+		}
+		if next := strings.Index(s, separator); next >= 0 {
+			val, s = s[:next], s[next+len(separator):]
+		} else {
+			val, s = s, ""
+		}
+		m[key] = strings.TrimSpace(val)
+	}
+	return m, true
+}
@@ -0,0 +1,232 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_envFormat_parseValue(t *testing.T) {
+	format := newEnvFormat(ConfigSource{})
+	tests := []struct {
+		val      string
+		expected interface{}
+	}{
+		{"plain", "plain"},
+		{"a=1,b=2", "a=1,b=2"},
+		{"[1, \"two\", true]", []interface{}{1.0, "two", true}},
+		{"[]", []interface{}{}},
+		{"[a,b,c]", []string{"a", "b", "c"}},
+		{"[a, b]", []string{"a", " b"}},
+		{"[\"a,b\", 'c d' ,\" e\"]", []string{"a,b", "c d", " e"}},
+		{"[\"say \\\"hi\\\"\",'it''s']", []string{"say \"hi\"", "it's"}},
+		{"[\"unterminated,b]", []string{"\"unterminated", "b"}},
+		{"{\"host\":\"a\",\"port\":5432}", map[string]interface{}{"host": "a", "port": 5432.0}},
+		{"{host=a, port=5432}", map[string]interface{}{"host": "a", "port": "5432"}},
+		{"{not a map}", "{not a map}"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, format.parseValue(test.val), test.val)
+	}
+	semicolon := newEnvFormat(ConfigSource{Separator: ";"})
+	assert.Equal(t, []string{"a,b", "c"}, semicolon.parseValue("[a,b;c]"))
+	assert.Equal(t, map[string]interface{}{"k1": "a,b", "k2": "c"}, semicolon.parseValue("{k1=a,b;k2=c}"))
+}
+
+func Test_parseKeyValues(t *testing.T) {
+	m, ok := parseKeyValues("k1=v1,k2=v2", ",")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, map[string]interface{}{"k1": "v1", "k2": "v2"}, m)
+	m, ok = parseKeyValues(" k1 = \"a,b\" , k2='c' ,, k3=", ",")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, map[string]interface{}{"k1": "a,b", "k2": "c", "k3": ""}, m)
+	m, ok = parseKeyValues("token=abc==", ",")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, map[string]interface{}{"token": "abc=="}, m)
+	_, ok = parseKeyValues("k1=v1,v2", ",")
+	assert.Equal(t, false, ok)
+	_, ok = parseKeyValues("=v1", ",")
+	assert.Equal(t, false, ok)
+}
+
+func Test_envSegments(t *testing.T) {
+	assert.Equal(t, []string{"0", "host"}, envSegments("0_HOST"))
+	assert.Equal(t, []string{"0", "max_conns"}, envSegments("0_MAX_CONNS"))
+	assert.Equal(t, []string{"0", "tags", "1"}, envSegments("0_TAGS_1"))
+	assert.Equal(t, []string{"host"}, envSegments("HOST"))
+}
+
+func Test_EnvSource_Indexed(t *testing.T) {
+	os.Setenv("APP_SERVERS_0_HOST", "a.example.com")
+	os.Setenv("APP_SERVERS_0_PORT", "80")
+	os.Setenv("APP_SERVERS_1_HOST", "b.example.com")
+	os.Setenv("APP_SERVERS_1_MAX_CONNS", "10")
+	os.Setenv("APP_SERVERS_1_TAGS", "[x,y]")
+	os.Setenv("APP_DATABASE", "{host=db.example.com,port=5432}")
+	os.Setenv("APP_TOKEN", "host=a,port=1")
+	os.Setenv("APP_LABELS", "{team=core,env=prod}")
+	os.Setenv("APP_LIMITS", "{\"cpu\":2}")
+	os.Setenv("SERVERS_0_HOST", "unprefixed")
+	defer func() {
+		for _, name := range []string{"APP_SERVERS_0_HOST", "APP_SERVERS_0_PORT", "APP_SERVERS_1_HOST", "APP_SERVERS_1_MAX_CONNS",
+			"APP_SERVERS_1_TAGS", "APP_DATABASE", "APP_TOKEN", "APP_LABELS", "APP_LIMITS", "SERVERS_0_HOST"} {
+			os.Unsetenv(name)
+		}
+	}()
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:   SourceTypeEnv,
+		Prefix: "APP",
+	})
+	assert.Equal(t, "env:APP", c.sources[0].provider.Name())
+	val, found := c.findKey("servers")
+	assert.Equal(t, true, found)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"host": "a.example.com", "port": "80"},
+		map[string]interface{}{"host": "b.example.com", "max_conns": "10", "tags": []string{"x", "y"}},
+	}, val)
+	assert.Equal(t, "a.example.com", c.GetStringOrDefault("servers[0].host", ""))
+	assert.Equal(t, 10, c.GetIntOrDefault("servers[1].max_conns", 0))
+	assert.Equal(t, "y", c.GetStringOrDefault("servers[1].tags[1]", ""))
+	// Keys inside the values of the parent variables
+	assert.Equal(t, "db.example.com", c.GetStringOrDefault("database.host", ""))
+	assert.Equal(t, 5432, c.GetIntOrDefault("database.port", 0))
+	assert.Equal(t, 2, c.GetIntOrDefault("limits.cpu", 0))
+	_, found = c.findKey("database.user")
+	assert.Equal(t, false, found)
+	database, err := Get[map[string]string](c, "database")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"host": "db.example.com", "port": "5432"}, database)
+	labels, err := Get[map[string]string](c, "labels")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, labels)
+	// Values are parsed as maps only in braces, so that plain values containing "=" stay strings everywhere
+	assert.Equal(t, "host=a,port=1", c.GetStringOrDefault("token", ""))
+	_, found = c.findKey("token.host")
+	assert.Equal(t, false, found)
+	_, err = Get[map[string]string](c, "token")
+	assert.NotNil(t, err)
+	type server struct {
+		Host     string
+		Port     int
+		MaxConns int      `gonfig:"max_conns"`
+		Tags     []string `gonfig:"tags"`
+	}
+	var cfg struct {
+		Servers []server `gonfig:"servers"`
+	}
+	assert.Nil(t, c.Unmarshal(&cfg))
+	assert.Equal(t, []server{
+		{Host: "a.example.com", Port: 80},
+		{Host: "b.example.com", MaxConns: 10, Tags: []string{"x", "y"}},
+	}, cfg.Servers)
+}
+
+func Test_EnvSource_DeepMerge(t *testing.T) {
+	os.Setenv("DATABASE_PASSWORD", "secret")
+	defer os.Unsetenv("DATABASE_PASSWORD")
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"json-host\"}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	val, found := c.findKey("database")
+	assert.Equal(t, true, found)
+	assert.Equal(t, map[string]interface{}{"host": "json-host", "password": "secret"}, val)
+}
+
+func Test_EnvSource_TopLevelKeys(t *testing.T) {
+	t.Setenv("USER", "root")
+	os.Setenv("DATABASE_USER", "admin")
+	defer os.Unsetenv("DATABASE_USER")
+	var c Configuration
+	mockFile("{\"user\":\"svc\",\"database\":{\"user\":\"app\"}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	// Top-level keys are not read from unrelated variables, but the nested keys are overridden by their conventional names
+	assert.Equal(t, "svc", c.GetStringOrDefault("user", ""))
+	assert.Equal(t, "admin", c.GetStringOrDefault("database.user", ""))
+	var cfg struct {
+		User string
+	}
+	assert.Nil(t, c.Unmarshal(&cfg))
+	assert.Equal(t, "svc", cfg.User)
+	// With a prefix, top-level keys are mapped too
+	os.Setenv("APP_USER", "app-user")
+	defer os.Unsetenv("APP_USER")
+	c = c.AddConfigSource(ConfigSource{
+		Type:   SourceTypeEnv,
+		Prefix: "APP",
+	})
+	assert.Equal(t, "app-user", c.GetStringOrDefault("user", ""))
+}
+
+func Test_EnvSource_ParentVariables(t *testing.T) {
+	os.Setenv("SERVERS", "[{\"host\":\"a\"}]")
+	os.Setenv("DATABASE", "{host=db,port=5432}")
+	defer os.Unsetenv("SERVERS")
+	defer os.Unsetenv("DATABASE")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	assert.Equal(t, "a", c.GetStringOrDefault("servers[0].host", ""))
+	assert.Equal(t, "db", c.GetStringOrDefault("database.host", ""))
+	assert.Equal(t, 5432, c.GetIntOrDefault("database.port", 0))
+	// The top-level keys themselves are still looked up as they are
+	_, found := c.findKey("servers")
+	assert.Equal(t, false, found)
+}
+
+func Test_EnvSource_AssembleComposite(t *testing.T) {
+	os.Setenv("JAVA_HOME", "/usr/lib/jvm")
+	os.Setenv("SERVERS_1_PORT", "8080")
+	defer os.Unsetenv("JAVA_HOME")
+	defer os.Unsetenv("SERVERS_1_PORT")
+	var c Configuration
+	mockFile("{\"java\":\"17\",\"servers\":[{\"port\":80},{\"port\":81}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	// Without a prefix, unrelated variables don't turn a scalar key into a map
+	assert.Equal(t, "17", c.GetStringOrDefault("java", ""))
+	// but the keys that are arrays or maps in the lower sources are assembled
+	c = c.SetMergeStrategy("servers", MergeByIndex)
+	assert.Equal(t, 8080, c.GetIntOrDefault("servers[1].port", 0))
+	assert.Equal(t, 80, c.GetIntOrDefault("servers[0].port", 0))
+}
+
+func Test_DotenvSource_Arrays(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:      SourceTypeDotenv,
+		Separator: ";",
+		Prefix:    "APP",
+		Data:      []byte("APP_HOSTS=[a,b;c]\nAPP_SERVERS_0_HOST=x\nAPP_SERVERS_1_HOST=y\nAPP_LABELS={team=core;env=prod}"),
+	})
+	hosts, err := c.GetStringArray("hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a,b", "c"}, hosts)
+	assert.Equal(t, "y", c.GetStringOrDefault("servers[1].host", ""))
+	servers, found := c.findKey("servers")
+	assert.Equal(t, true, found)
+	assert.Equal(t, 2, len(servers.([]interface{})))
+	assert.Equal(t, "prod", c.GetStringOrDefault("labels.env", ""))
+	labels, err := Get[map[string]string](c, "labels")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, labels)
+}
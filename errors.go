@@ -0,0 +1,262 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl/hcl/parser"
+)
+
+var (
+	// ErrKeyNotFound is returned when none of the config sources have the key
+	ErrKeyNotFound = errors.New("The key is not found among config sources")
+	// ErrNotArray is returned by the array getters when the value of the key is not an array
+	ErrNotArray = errors.New("The value is not an array or slice")
+
+	errUnknownType  = errors.New("Unknown type")
+	errUnknownValue = errors.New("Unknown value")
+	errOverflow     = errors.New("The value overflows the type")
+	errNotIntegral  = errors.New("The value is not an integer")
+	// errIndexTooLarge is returned by setPath for the keys like "a[999999999]", see maxArrayIndex
+	errIndexTooLarge = errors.New("The array index is too large")
+)
+
+// ConversionError is returned when the value of a key cannot be converted to the requested type
+type ConversionError struct {
+	// Key is the key whose value is converted. It's empty if the value isn't looked up by a key
+	Key string
+	// Value is the raw value read from the config source
+	Value interface{}
+	// Type is the name of the requested type, e.g. "int"
+	Type string
+	// Source is the name of the config source that supplied the value
+	Source string
+	// Err is the reason of the failure
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("Cannot convert %v (%T) to %s", e.Value, e.Value, e.Type)
+	if e.Key != "" {
+		msg += fmt.Sprintf(" for the key %s", e.Key)
+	}
+	if e.Source != "" {
+		msg += fmt.Sprintf(" from %s", e.Source)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// withKey adds the key and the source of the value to a *ConversionError, and returns other errors as they are
+func withKey(err error, key string, source string) error {
+	if convErr, ok := err.(*ConversionError); ok && convErr.Key == "" {
+		withKey := *convErr
+		withKey.Key, withKey.Source = key, source
+		return &withKey
+	}
+	return err
+}
+
+// withType sets the type of a *ConversionError to the given type, or wraps other errors into a *ConversionError of the type
+func withType(err error, val interface{}, rt reflect.Type) error {
+	if convErr, ok := err.(*ConversionError); ok {
+		withType := *convErr
+		withType.Type = rt.String()
+		return &withType
+	}
+	return &ConversionError{Value: val, Type: rt.String(), Err: err}
+}
+
+// ParseError is returned when the content of a source cannot be parsed
+type ParseError struct {
+	// Format is the format that's parsed, e.g. "json"
+	Format string
+	// Line is the line number of the error starting at 1, or 0 if it's unknown
+	Line int
+	// Column is the column number of the error starting at 1, or 0 if it's unknown
+	Column int
+	// Msg is the description of the error
+	Msg string
+	// Err is the error returned by the underlying decoder, if any
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Column > 0 {
+		return fmt.Sprintf("%s: line %d, column %d: %s", e.Format, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s: line %d: %s", e.Format, e.Line, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// SourceError describes a config source that could not be loaded
+type SourceError struct {
+	// Type is the SourceType of the source, or empty if it's added with AddSource
+	Type SourceType
+	// FilePath is the path of the file of the source, if any
+	FilePath string
+	// Source is the name of the source
+	Source string
+	// Line is the line number of the parse error starting at 1, or 0 if it's not a parse error or the line is unknown
+	Line int
+	// Column is the column number of the parse error starting at 1, or 0 if it's not a parse error or the column is unknown
+	Column int
+	// Err is the error returned while loading the source
+	Err error
+}
+
+func (e SourceError) Error() string {
+	location := e.Source
+	if e.FilePath != "" {
+		location = e.FilePath
+	}
+	if location == "" {
+		return e.Err.Error()
+	}
+	if e.Line > 0 && e.Column > 0 {
+		location = fmt.Sprintf("%s:%d:%d", location, e.Line, e.Column)
+	} else if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+	return fmt.Sprintf("%s: %v", location, e.Err)
+}
+
+func (e SourceError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError is returned by Err when one or more config sources could not be loaded
+type LoadError struct {
+	Sources []SourceError
+}
+
+func (e *LoadError) Error() string {
+	messages := make([]string, 0, len(e.Sources))
+	for _, source := range e.Sources {
+		messages = append(messages, source.Error())
+	}
+	return fmt.Sprintf("%d config source(s) could not be loaded: %s", len(e.Sources), strings.Join(messages, "; "))
+}
+
+// Is reports whether the error of any of the sources matches the target, so that errors.Is can match them
+func (e *LoadError) Is(target error) bool {
+	for _, source := range e.Sources {
+		if errors.Is(source, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of the sources that matches the target, so that errors.As can match them
+func (e *LoadError) As(target interface{}) bool {
+	for _, source := range e.Sources {
+		if errors.As(source, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the errors of the config sources that could not be loaded, in the order they are added,
+// followed by the errors of the keys that could not be set by SetDefault and Set
+func (c Configuration) Errors() []SourceError {
+	var errs []SourceError
+	for _, loadedSource := range c.sources {
+		if loadedSource.err == nil {
+			continue
+		}
+		errs = append(errs, newSourceError(loadedSource))
+	}
+	return append(errs, c.setErrors...)
+}
+
+func newSourceError(loaded loadedSource) SourceError {
+	sourceErr := SourceError{
+		Type:     loaded.source.Type,
+		FilePath: loaded.source.FilePath,
+		Err:      loaded.err,
+	}
+	if loaded.provider != nil {
+		sourceErr.Source = loaded.provider.Name()
+	}
+	var parseErr *ParseError
+	if errors.As(loaded.err, &parseErr) {
+		sourceErr.Line, sourceErr.Column = parseErr.Line, parseErr.Column
+	}
+	return sourceErr
+}
+
+// Err returns a *LoadError that joins the errors of the config sources that could not be loaded, or nil if all of them are loaded
+func (c Configuration) Err() error {
+	if errs := c.Errors(); len(errs) > 0 {
+		return &LoadError{Sources: errs}
+	}
+	return nil
+}
+
+// yamlLinePattern matches the line number in the errors of the yaml decoder, e.g. "yaml: line 3: did not find expected key"
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// toParseError wraps the error of a decoder into a *ParseError with the position of the error, if it can be found
+func toParseError(format SourceType, data []byte, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+	parseErr = &ParseError{Format: string(format), Err: err}
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var tomlErr toml.ParseError
+	var hclErr *parser.PosError
+	switch {
+	case errors.As(err, &jsonSyntaxErr):
+		parseErr.Line, parseErr.Column = offsetPosition(data, int(jsonSyntaxErr.Offset))
+	case errors.As(err, &jsonTypeErr):
+		parseErr.Line, parseErr.Column = offsetPosition(data, int(jsonTypeErr.Offset))
+	case errors.As(err, &tomlErr):
+		parseErr.Line, parseErr.Column = offsetPosition(data, tomlErr.Position.Start)
+	case errors.As(err, &hclErr):
+		parseErr.Line, parseErr.Column = hclErr.Pos.Line, hclErr.Pos.Column
+	default:
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			parseErr.Line, _ = strconv.Atoi(match[1])
+		}
+	}
+	return parseErr
+}
+
+// offsetPosition converts a byte offset in the data to line and column numbers starting at 1
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line, column := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
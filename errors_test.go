@@ -0,0 +1,162 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Errors(t *testing.T) {
+	var c Configuration
+	mockFile("{\"key1\":\"value1\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "good.json",
+	})
+	assert.Nil(t, c.Errors())
+	assert.Nil(t, c.Err())
+	mockFile("", os.ErrNotExist)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "missing.yaml",
+	})
+	mockFile("{\n  \"key1\": \"value1\",\n  \"key2\": ,\n}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "broken.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: "unknown",
+	})
+	assert.Equal(t, true, c.HasError)
+	errs := c.Errors()
+	assert.Equal(t, 3, len(errs))
+	assert.Equal(t, SourceType(SourceTypeYaml), errs[0].Type)
+	assert.Equal(t, "missing.yaml", errs[0].FilePath)
+	assert.Equal(t, "yaml:missing.yaml", errs[0].Source)
+	assert.True(t, errors.Is(errs[0], os.ErrNotExist))
+	assert.Equal(t, 0, errs[0].Line)
+	assert.Equal(t, "broken.json", errs[1].FilePath)
+	assert.Equal(t, 3, errs[1].Line)
+	assert.Equal(t, 12, errs[1].Column)
+	assert.EqualError(t, errs[1], "broken.json:3:12: invalid character ',' looking for beginning of value")
+	var parseErr *ParseError
+	assert.True(t, errors.As(errs[1], &parseErr))
+	assert.Equal(t, "json", parseErr.Format)
+	assert.EqualError(t, errs[2], "Unknown source type \"unknown\"")
+	err := c.Err()
+	var loadErr *LoadError
+	assert.True(t, errors.As(err, &loadErr))
+	assert.Equal(t, errs, loadErr.Sources)
+	assert.Contains(t, err.Error(), "3 config source(s) could not be loaded: missing.yaml: ")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 3, parseErr.Line)
+	assert.False(t, errors.Is(err, os.ErrPermission))
+}
+
+func Test_toParseError(t *testing.T) {
+	tests := []struct {
+		format SourceType
+		data   string
+		decode Decoder
+		line   int
+		column int
+	}{
+		{SourceTypeYaml, "key1: value1\nkey2: \"value2\tkey3: value3", decodeYaml, 2, 0},
+		{SourceTypeTOML, "key1 = \"value1\"\nkey2 = value2", decodeTOML, 2, 8},
+		{SourceTypeHCL, "key1 = \"value1\"\nkey2 = {", decodeHCL, 2, 10},
+		{SourceTypeDotenv, "KEY1=value1\nKEY2", decodeDotenv, 2, 0},
+		{SourceTypeINI, "[section\nkey = value", decodeINI, 1, 0},
+		{SourceTypeProperties, "key1 = value1\nkey2 = \\u12", decodeProperties, 2, 0},
+	}
+	for _, test := range tests {
+		_, err := test.decode([]byte(test.data))
+		assert.NotNil(t, err, test.format)
+		err = toParseError(test.format, []byte(test.data), err)
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr), test.format)
+		assert.Equal(t, test.line, parseErr.Line, test.format)
+		assert.Equal(t, test.column, parseErr.Column, test.format)
+	}
+}
+
+func Test_ParseError(t *testing.T) {
+	err := &ParseError{Format: "ini", Line: 2, Column: 5, Msg: "empty key"}
+	assert.EqualError(t, err, "ini: line 2, column 5: empty key")
+	wrapped := &ParseError{Format: "json", Line: 1, Err: errors.New("decoder error")}
+	assert.EqualError(t, wrapped, "decoder error")
+	assert.EqualError(t, errors.Unwrap(wrapped), "decoder error")
+}
+
+func Test_offsetPosition(t *testing.T) {
+	data := []byte("ab\ncd\nef")
+	line, column := offsetPosition(data, 0)
+	assert.Equal(t, []int{1, 1}, []int{line, column})
+	line, column = offsetPosition(data, 4)
+	assert.Equal(t, []int{2, 2}, []int{line, column})
+	line, column = offsetPosition(data, 100)
+	assert.Equal(t, []int{3, 3}, []int{line, column})
+}
+
+func Test_ConversionError(t *testing.T) {
+	var c Configuration
+	mockFile("{\"port\":\"http\", \"debug\":\"maybe\", \"ratio\":[1]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	_, err := c.GetInt("port")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "port", convErr.Key)
+	assert.Equal(t, "http", convErr.Value)
+	assert.Equal(t, "int", convErr.Type)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+	assert.EqualError(t, err, "Cannot convert http (string) to int for the key port from json:testing.json: strconv.ParseInt: parsing \"http\": invalid syntax")
+	_, err = c.GetBool("debug")
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "bool", convErr.Type)
+	assert.ErrorIs(t, err, errUnknownValue)
+	_, err = c.GetFloat("ratio")
+	assert.True(t, errors.As(err, &convErr))
+	assert.ErrorIs(t, err, errUnknownType)
+	// Lookup errors
+	_, err = c.GetInt("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = c.GetIntArray("port")
+	assert.ErrorIs(t, err, ErrNotArray)
+	_, err = c.GetStringArray("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	// Unmarshal reports the conversion errors of the fields
+	var cfg struct {
+		Port  int8   `gonfig:"port"`
+		Ratio []int  `gonfig:"ratio"`
+		Debug string `gonfig:"debug"`
+	}
+	err = c.Unmarshal(&cfg)
+	var unmarshalErr *UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 1, len(unmarshalErr.Fields))
+	assert.True(t, errors.As(unmarshalErr.Fields[0].Err, &convErr))
+	assert.Equal(t, "port", convErr.Key)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+}
+
+func Test_ConversionError_Overflow(t *testing.T) {
+	var c Configuration
+	c = c.AddSource(NewMapSource(map[string]interface{}{"big": 300, "negative": -1}))
+	var cfg struct {
+		Big      int8  `gonfig:"big"`
+		Negative uint8 `gonfig:"negative"`
+	}
+	err := c.Unmarshal(&cfg)
+	var unmarshalErr *UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 2, len(unmarshalErr.Fields))
+	for _, field := range unmarshalErr.Fields {
+		assert.ErrorIs(t, field.Err, errOverflow)
+	}
+}
@@ -0,0 +1,136 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// AllSettings returns the merged configuration as nested maps and arrays.
+// Every key is resolved through all the config sources like the getters do, so the env source is included for the keys it overrides,
+// and the arrays that have a merge strategy are merged as a whole.
+// Keys are enumerated from the sources that implement Enumerable, see Provenance.
+func (c Configuration) AllSettings() map[string]interface{} {
+	settings := make(map[string]interface{})
+	resolved := make(map[string]bool)
+	for _, key := range c.allKeys() {
+		if ancestor, _, ok := c.strategyAncestor(key); ok {
+			key = ancestor
+		}
+		if resolved[key] {
+			continue
+		}
+		resolved[key] = true
+		if val, found := c.findKey(key); found {
+			setPath(settings, key, stringKeys(val))
+		}
+	}
+	return settings
+}
+
+// WriteJSON writes the merged configuration to w as indented JSON
+func (c Configuration) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c.AllSettings())
+}
+
+// WriteYAML writes the merged configuration to w as YAML
+func (c Configuration) WriteYAML(w io.Writer) error {
+	out, err := yaml.Marshal(c.AllSettings())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// WriteEnv writes the merged configuration to w as KEY=value lines, sorted by the keys.
+// Nested keys are converted to their conventional environment variable names like DATABASE_HOST, and top-level keys are written as they are,
+// like the env source without a prefix reads them. Arrays are written as JSON like TAGS=["a","b"], so that they're read back as whole arrays.
+// Values that contain spaces, quotes or special characters are double quoted, so the output can be read by the dotenv source.
+func (c Configuration) WriteEnv(w io.Writer) error {
+	leaves := make(map[string]interface{})
+	envLeaves(c.AllSettings(), "", leaves)
+	lines := make([]string, 0, len(leaves))
+	for key, val := range leaves {
+		name := key
+		if strings.Contains(key, ".") {
+			name = envKey(key)
+		}
+		value, err := envValue(val)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s=%s", name, quoteEnvValue(value)))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envLeaves returns the values of the nested maps that are not maps themselves, keyed by their dotted paths.
+// Unlike flatten, arrays are leaves.
+func envLeaves(value map[string]interface{}, prefix string, output map[string]interface{}) {
+	for key, item := range value {
+		if m, ok := item.(map[string]interface{}); ok && len(m) > 0 {
+			envLeaves(m, joinKey(prefix, key), output)
+			continue
+		}
+		output[joinKey(prefix, key)] = item
+	}
+}
+
+// envValue converts a leaf value to the string representation the env source reads
+func envValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil, map[string]interface{}:
+		return "", nil // Empty maps
+	case []interface{}, []string:
+		out, err := json.Marshal(v)
+		return string(out), err
+	}
+	return convertToString(val), nil
+}
+
+// quoteEnvValue double quotes a value if it cannot be written as it is
+func quoteEnvValue(val string) string {
+	if !strings.ContainsAny(val, " \t\r\n\"'\\$#=") {
+		return val
+	}
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\n", "\\n", "\r", "\\r", "\t", "\\t")
+	return "\"" + replacer.Replace(val) + "\""
+}
+
+// stringKeys converts the map[interface{}]interface{} values produced by the yaml decoder to map[string]interface{}, so that they can be encoded as JSON
+func stringKeys(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[convertToString(key)] = stringKeys(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = stringKeys(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = stringKeys(item)
+		}
+		return arr
+	}
+	return val
+}
@@ -0,0 +1,142 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func Test_AllSettings(t *testing.T) {
+	os.Setenv("DATABASE_HOST", "env-host")
+	defer os.Unsetenv("DATABASE_HOST")
+	var c Configuration
+	c = c.SetDefault("log.level", "info")
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}, \"servers\":[{\"port\":80},{\"port\":81}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	mockFile("database:\n  user: admin\n  password: \"p@ss word\"\ntags:\n  - a\n  - b", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	c = c.Set("servers[1].port", 8081)
+	assert.Equal(t, map[string]interface{}{
+		"log": map[string]interface{}{"level": "info"},
+		"database": map[string]interface{}{
+			"host":     "env-host",
+			"port":     5432.0,
+			"user":     "admin",
+			"password": "p@ss word",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"port": 80.0},
+			map[string]interface{}{"port": 8081},
+		},
+		"tags": []interface{}{"a", "b"},
+	}, c.AllSettings())
+	var empty Configuration
+	assert.Equal(t, map[string]interface{}{}, empty.AllSettings())
+}
+
+func Test_WriteJSON(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}, \"servers\":[{\"port\":80},{\"port\":81}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	c = c.Set("servers[1].port", 8081)
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteJSON(&buf))
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "json-host", decoded["database"].(map[string]interface{})["host"])
+	assert.Equal(t, 8081.0, decoded["servers"].([]interface{})[1].(map[string]interface{})["port"])
+	assert.Contains(t, buf.String(), "\n  \"database\": {\n")
+}
+
+func Test_WriteYAML(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("log.level", "info")
+	mockFile("tags:\n  - a\n  - b", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteYAML(&buf))
+	var decoded map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "info", decoded["log"].(map[interface{}]interface{})["level"])
+	assert.Equal(t, []interface{}{"a", "b"}, decoded["tags"])
+}
+
+func Test_WriteEnv(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("log.level", "info")
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}, \"servers\":[{\"port\":80},{\"port\":81}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	mockFile("database:\n  user: admin\n  password: \"p@ss word\"\ntags:\n  - a\n  - b", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	c = c.Set("servers[1].port", 8081)
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteEnv(&buf))
+	assert.Equal(t, "DATABASE_HOST=json-host\n"+
+		"DATABASE_PASSWORD=\"p@ss word\"\n"+
+		"DATABASE_PORT=5432\n"+
+		"DATABASE_USER=admin\n"+
+		"LOG_LEVEL=info\n"+
+		"servers=\"[{\\\"port\\\":80},{\\\"port\\\":8081}]\"\n"+
+		"tags=\"[\\\"a\\\",\\\"b\\\"]\"\n", buf.String())
+	// The output can be read back by the dotenv source
+	var reloaded Configuration
+	reloaded = reloaded.AddConfigSource(ConfigSource{
+		Type: SourceTypeDotenv,
+		Data: buf.Bytes(),
+	})
+	password, err := reloaded.GetString("database.password")
+	assert.Nil(t, err)
+	assert.Equal(t, "p@ss word", password)
+	port, err := reloaded.GetInt("servers[1].port")
+	assert.Nil(t, err)
+	assert.Equal(t, 8081, port)
+	tags, err := reloaded.GetStringArray("tags")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+	servers, err := Get[[]map[string]int](reloaded, "servers")
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]int{{"port": 80}, {"port": 8081}}, servers)
+	// The arrays of the env sources are written as JSON too
+	buf.Reset()
+	assert.Nil(t, reloaded.AddConfigSource(ConfigSource{
+		Type: SourceTypeDotenv,
+		Data: []byte("name=svc\nhosts=[a,b]"),
+	}).WriteEnv(&buf))
+	assert.Contains(t, buf.String(), "\nhosts=\"[\\\"a\\\",\\\"b\\\"]\"\n")
+	assert.Contains(t, buf.String(), "\nname=svc\n")
+}
+
+func Test_quoteEnvValue(t *testing.T) {
+	assert.Equal(t, "plain", quoteEnvValue("plain"))
+	assert.Equal(t, "", quoteEnvValue(""))
+	assert.Equal(t, "\"a b\"", quoteEnvValue("a b"))
+	assert.Equal(t, "\"say \\\"hi\\\"\\n\\$HOME\"", quoteEnvValue("say \"hi\"\n$HOME"))
+}
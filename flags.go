@@ -0,0 +1,152 @@
+package gonfig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// flagSource is a Source that reads --key=value style command-line arguments
+type flagSource struct {
+	args  []string
+	items map[string]interface{}
+}
+
+// NewFlagSource creates a Source from the given command-line arguments, e.g. os.Args[1:].
+// Flags can be given as --key=value, --key value or -key=value, and dotted keys like --db.host are nested.
+// A flag without a value is set to "true", and --no-key sets key to "false". Negative numbers like --offset -5 are values, not flags.
+// Repeated flags are collected into a []string, and a flag that's given once is read as an array of one item by the array getters and Unmarshal.
+// Arguments after "--" and arguments that are not flags are ignored.
+// Add the source last to let the command-line override every other source.
+func NewFlagSource(args []string) Source {
+	return &flagSource{args: args}
+}
+
+func newFlagSourceFromConfig(s ConfigSource) Source {
+	if s.Args == nil {
+		return NewFlagSource(os.Args[1:])
+	}
+	return NewFlagSource(s.Args)
+}
+
+func (s *flagSource) Name() string {
+	return string(SourceTypeFlags)
+}
+
+func (s *flagSource) Load() error {
+	items, err := parseFlags(s.args)
+	if err != nil {
+		return err
+	}
+	s.items = items
+	return nil
+}
+
+func (s *flagSource) Lookup(key string) (interface{}, bool) {
+	return lookupPath(s.items, key)
+}
+
+func (s *flagSource) Settings() map[string]interface{} {
+	return s.items
+}
+
+func parseFlags(args []string) (map[string]interface{}, error) {
+	items := make(map[string]interface{})
+	values := make(map[string]interface{})
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if name == "" || name[0] == '-' || name[0] == '=' {
+			continue
+		}
+		var value string
+		if eq := strings.Index(name, "="); eq >= 0 {
+			name, value = name[:eq], name[eq+1:]
+		} else if strings.HasPrefix(name, "no-") {
+			name, value = name[len("no-"):], "false"
+		} else if i+1 < len(args) && (!strings.HasPrefix(args[i+1], "-") || isNumber(args[i+1])) {
+			i++
+			value = args[i]
+		} else {
+			value = "true"
+		}
+		switch existing := values[name].(type) {
+		case nil:
+			values[name] = value
+		case string:
+			values[name] = []string{existing, value}
+		case []string:
+			values[name] = append(existing, value)
+		}
+	}
+	// Set in a sorted order, so that the conflicts like --log and --log.level are settled the same way every time
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := setPath(items, name, values[name]); err != nil {
+			return nil, fmt.Errorf("%w in the flag --%s", err, name)
+		}
+	}
+	return items, nil
+}
+
+// isNumber returns true if the argument is a number like -5 or -0.5, which is the value of the preceding flag rather than a flag
+func isNumber(arg string) bool {
+	if len(arg) < 2 || !strings.ContainsAny(arg[1:2], "0123456789.") {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+// flagSetSource is a Source that reads the flags of a flag.FlagSet
+type flagSetSource struct {
+	flags *flag.FlagSet
+	items map[string]interface{}
+}
+
+// NewFlagSetSource creates a Source from the flags of a parsed flag.FlagSet, so that existing flags can feed the configuration.
+// Only the flags that are set on the command-line are used, so the defaults of the flags don't override the other sources.
+// The FlagSet must be parsed before the source is added.
+func NewFlagSetSource(fs *flag.FlagSet) Source {
+	return &flagSetSource{flags: fs}
+}
+
+func (s *flagSetSource) Name() string {
+	return string(SourceTypeFlags) + ":" + s.flags.Name()
+}
+
+func (s *flagSetSource) Load() error {
+	s.items = make(map[string]interface{})
+	var err error
+	s.flags.Visit(func(f *flag.Flag) {
+		var value interface{} = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+		if setErr := setPath(s.items, f.Name, value); setErr != nil && err == nil {
+			err = fmt.Errorf("%w in the flag -%s", setErr, f.Name)
+		}
+	})
+	return err
+}
+
+func (s *flagSetSource) Lookup(key string) (interface{}, bool) {
+	return lookupPath(s.items, key)
+}
+
+func (s *flagSetSource) Settings() map[string]interface{} {
+	return s.items
+}
@@ -0,0 +1,96 @@
+package gonfig
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseFlags(t *testing.T) {
+	args := []string{"serve", "--db.host=localhost", "--db.port", "5432", "-verbose", "--no-color", "--tag", "a", "--tag=b", "--tag", "c", "--debug", "--", "--ignored=1"}
+	result, err := parseFlags(args)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"verbose": "true",
+		"color":   "false",
+		"tag":     []string{"a", "b", "c"},
+		"debug":   "true",
+	}, result)
+	result, err = parseFlags([]string{"--offset", "-5", "--ratio", "-0.5", "--name", "x", "-v", "-inf"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"offset": "-5", "ratio": "-0.5", "name": "x", "v": "true", "inf": "true"}, result)
+	// Parent and child conflicts are settled the same way regardless of the order
+	for i := 0; i < 10; i++ {
+		result, err = parseFlags([]string{"--log.level=debug", "--log=info"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"log": "info", "log.level": "debug"}, result)
+	}
+	_, err = parseFlags([]string{"--a[0]=x", "--a[999999999]=y"})
+	assert.ErrorIs(t, err, errIndexTooLarge)
+}
+
+func Test_AddConfigSource_Flags(t *testing.T) {
+	var c Configuration
+	mockFile("{\"db\":{\"host\":\"localhost\",\"port\":5432}, \"color\":1}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeFlags,
+		Args: []string{"--db.port=6543", "--no-color", "--ports", "80", "--ports", "443", "--tag", "a"},
+	})
+	assert.Equal(t, false, c.HasError)
+	port, err := c.GetInt("db.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 6543, port)
+	host, err := c.GetString("db.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", host)
+	ports, err := c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	// A flag that's given once is an array of one item
+	tags, err := c.GetStringArray("tag")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, tags)
+	var settings struct {
+		Tags  []string `gonfig:"tag"`
+		Ports []int
+	}
+	assert.Nil(t, c.Unmarshal(&settings))
+	assert.Equal(t, []string{"a"}, settings.Tags)
+	assert.Equal(t, []int{80, 443}, settings.Ports)
+	// The values of the other sources are not
+	hosts, err := c.GetStringArray("db.host")
+	assert.EqualError(t, err, "The value is not an array or slice")
+	assert.Nil(t, hosts)
+}
+
+func Test_NewFlagSetSource(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("db.port", 5432, "database port")
+	fs.String("db.host", "localhost", "database host")
+	err := fs.Parse([]string{"-db.port=6543"})
+	assert.Nil(t, err)
+	var c Configuration
+	mockFile("{\"db\":{\"host\":\"db.internal\"}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddSource(NewFlagSetSource(fs))
+	assert.Equal(t, "flags:app", c.sources[1].provider.Name())
+	port, err := c.GetInt("db.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 6543, port)
+	// Flags that are not set don't override the other sources with their defaults
+	host, err := c.GetString("db.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "db.internal", host)
+}
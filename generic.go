@@ -0,0 +1,113 @@
+package gonfig
+
+import (
+	"encoding"
+	"reflect"
+	"time"
+)
+
+// Converter converts a raw value read from a config source, e.g. a string, a number, an array or a map, to a custom type
+type Converter[T any] func(val interface{}) (T, error)
+
+var (
+	converters = map[reflect.Type]func(interface{}) (interface{}, error){}
+
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RegisterConverter makes a custom type available to Get, GetOr and Unmarshal, e.g. RegisterConverter(func(val interface{}) (*url.URL, error) {...}).
+// Types implementing encoding.TextUnmarshaler don't need to be registered, but their converters take precedence if they are.
+// Registering a type that's already registered replaces the previous converter.
+func RegisterConverter[T any](convert Converter[T]) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	converters[reflect.TypeOf((*T)(nil)).Elem()] = func(val interface{}) (interface{}, error) {
+		return convert(val)
+	}
+}
+
+// Get returns the value of the key converted to T if the key is amongst the config sources and if the value is convertable to T.
+// T can be any scalar type, time.Duration, time.Time, a slice, a map with string keys, a struct, a pointer to one of them,
+// a type registered with RegisterConverter, or a type implementing encoding.TextUnmarshaler.
+// Returns an error otherwise
+func Get[T any](c Configuration, key string) (T, error) {
+	var result T
+	val, source, found := c.lookup(key)
+	if !found {
+		return result, ErrKeyNotFound
+	}
+	d := decoder{config: c}
+	if err := d.decodeValue(key, source, val, reflect.ValueOf(&result).Elem()); err != nil {
+		var zero T
+		return zero, withKey(err, key, source)
+	}
+	return result, nil
+}
+
+// GetOr returns the value of the key converted to T if the key is amongst the config sources and if the value is convertable to T
+// Returns the default value otherwise
+func GetOr[T any](c Configuration, key string, defaultValue T) T {
+	if val, err := Get[T](c, key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// lookupConverter returns the converter registered for the type, if any
+func lookupConverter(rt reflect.Type) (func(interface{}) (interface{}, error), bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	convert, found := converters[rt]
+	return convert, found
+}
+
+// hasConverter returns true if the values of the type are converted as a whole rather than field by field
+func hasConverter(rt reflect.Type) bool {
+	if _, found := lookupConverter(rt); found {
+		return true
+	}
+	return rt == durationType || rt == timeType || isTextUnmarshaler(rt)
+}
+
+// convertValue sets rv to the value returned by a registered converter
+func convertValue(val interface{}, rv reflect.Value, convert func(interface{}) (interface{}, error)) error {
+	converted, err := convert(val)
+	if err != nil {
+		if _, ok := err.(*ConversionError); ok {
+			return err
+		}
+		return &ConversionError{Value: val, Type: rv.Type().String(), Err: err}
+	}
+	if converted == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	rv.Set(reflect.ValueOf(converted))
+	return nil
+}
+
+// isTextUnmarshaler returns true if the pointers of the type implement encoding.TextUnmarshaler
+func isTextUnmarshaler(rt reflect.Type) bool {
+	return rt.Kind() != reflect.Ptr && reflect.PtrTo(rt).Implements(textUnmarshalerType)
+}
+
+// unmarshalText sets rv, whose pointer implements encoding.TextUnmarshaler, from the string representation of the value
+func unmarshalText(val interface{}, rv reflect.Value) error {
+	ptr := reflect.New(rv.Type())
+	if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(convertToString(val))); err != nil {
+		return &ConversionError{Value: val, Type: rv.Type().String(), Err: err}
+	}
+	rv.Set(ptr.Elem())
+	return nil
+}
+
+// isCompositeValue returns true if the raw value is a map or an array
+func isCompositeValue(val interface{}) bool {
+	if _, ok := toMap(val); ok {
+		return true
+	}
+	_, ok := toArray(val)
+	return ok
+}
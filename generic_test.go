@@ -0,0 +1,212 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func Test_Get_BuiltinTypes(t *testing.T) {
+	var c Configuration
+	mockFile("{\"port\":8080, \"ratio\":\"0.5\", \"name\":\"api\", \"debug\":\"yes\", \"ports\":[80, \"443\"],"+
+		"\"limits\":{\"cpu\":2, \"memory\":4}, \"timeout\":\"1m\", \"started\":\"2022-03-04\", \"database\":{\"host\":\"localhost\", \"port\":5432}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	port, err := Get[int](c, "port")
+	assert.Nil(t, err)
+	assert.Equal(t, 8080, port)
+	port16, err := Get[uint16](c, "port")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(8080), port16)
+	ratio, err := Get[float64](c, "ratio")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, ratio)
+	name, err := Get[string](c, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "api", name)
+	debug, err := Get[bool](c, "debug")
+	assert.Nil(t, err)
+	assert.Equal(t, true, debug)
+	ports, err := Get[[]int](c, "ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	limits, err := Get[map[string]int](c, "limits")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"cpu": 2, "memory": 4}, limits)
+	timeout, err := Get[time.Duration](c, "timeout")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, timeout)
+	started, err := Get[time.Time](c, "started")
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC).Equal(started))
+	namePtr, err := Get[*string](c, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "api", *namePtr)
+	raw, err := Get[interface{}](c, "port")
+	assert.Nil(t, err)
+	assert.Equal(t, 8080.0, raw)
+	type database struct {
+		Host string
+		Port int
+	}
+	db, err := Get[database](c, "database")
+	assert.Nil(t, err)
+	assert.Equal(t, database{Host: "localhost", Port: 5432}, db)
+}
+
+func Test_Get_Errors(t *testing.T) {
+	var c Configuration
+	mockFile("{\"port\":8080, \"debug\":\"yes\", \"bad\":\"x\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	_, err := Get[int](c, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	val, err := Get[int](c, "bad")
+	assert.Equal(t, 0, val)
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "bad", convErr.Key)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+	_, err = Get[int8](c, "port")
+	assert.ErrorIs(t, err, errOverflow)
+	_, err = Get[[]int](c, "port")
+	assert.ErrorIs(t, err, ErrNotArray)
+	c.StrictBool = true
+	_, err = Get[bool](c, "debug")
+	assert.ErrorIs(t, err, errUnknownValue)
+}
+
+func Test_GetOr(t *testing.T) {
+	var c Configuration
+	mockFile("{\"port\":8080, \"name\":\"api\", \"bad\":\"x\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	assert.Equal(t, 8080, GetOr(c, "port", 1))
+	assert.Equal(t, 1, GetOr(c, "bad", 1))
+	assert.Equal(t, []string{"a"}, GetOr(c, "missing", []string{"a"}))
+	assert.Equal(t, time.Second, GetOr(c, "name", time.Second))
+}
+
+func Test_Get_TextUnmarshaler(t *testing.T) {
+	var c Configuration
+	mockFile("{\"name\":\"api\", \"addr\":\"10.0.0.1\", \"addrs\":[\"10.0.0.1\", \"::1\"], \"level\":\"info\", \"levels\":[\"debug\", \"error\"]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	addr, err := Get[netip.Addr](c, "addr")
+	assert.Nil(t, err)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+	addrs, err := Get[[]netip.Addr](c, "addrs")
+	assert.Nil(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1")}, addrs)
+	level, err := Get[testLevel](c, "level")
+	assert.Nil(t, err)
+	assert.Equal(t, testLevel(1), level)
+	levels, err := Get[[]testLevel](c, "levels")
+	assert.Nil(t, err)
+	assert.Equal(t, []testLevel{0, 2}, levels)
+	levelPtr, err := Get[*testLevel](c, "level")
+	assert.Nil(t, err)
+	assert.Equal(t, testLevel(1), *levelPtr)
+	_, err = Get[testLevel](c, "name")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "gonfig.testLevel", convErr.Type)
+	assert.EqualError(t, errors.Unwrap(err), "unknown level \"api\"")
+}
+
+func Test_RegisterConverter(t *testing.T) {
+	RegisterConverter(func(val interface{}) (*url.URL, error) {
+		return url.Parse(convertToString(val))
+	})
+	defer func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		delete(converters, reflect.TypeOf(&url.URL{}))
+	}()
+	var c Configuration
+	mockFile("{\"endpoint\":\"https://example.com/api\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	endpoint, err := Get[*url.URL](c, "endpoint")
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com", endpoint.Host)
+	_, err = Get[*url.URL](c, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	mockFile("{\"endpoint\":\":bad\"}", nil)
+	bad := c.AddConfigSource(ConfigSource{Type: SourceTypeJSON, FilePath: "bad.json"})
+	_, err = Get[*url.URL](bad, "endpoint")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "*url.URL", convErr.Type)
+	assert.Equal(t, "json:bad.json", convErr.Source)
+}
+
+func Test_Unmarshal_Converters(t *testing.T) {
+	RegisterConverter(func(val interface{}) (*url.URL, error) {
+		return url.Parse(convertToString(val))
+	})
+	defer func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		delete(converters, reflect.TypeOf(&url.URL{}))
+	}()
+	var c Configuration
+	mockFile("{\"timeout\":\"1m\", \"started\":\"2022-03-04\", \"addr\":\"10.0.0.1\", \"level\":\"info\", \"endpoint\":\"https://example.com/api\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{Type: SourceTypeEnv})
+	os.Setenv("retry", "250")
+	defer os.Unsetenv("retry")
+	c.DurationUnit = time.Millisecond
+	var cfg struct {
+		Timeout  time.Duration `gonfig:"timeout"`
+		Retry    time.Duration `gonfig:"retry"`
+		Started  time.Time     `gonfig:"started"`
+		Addr     netip.Addr    `gonfig:"addr"`
+		Level    *testLevel    `gonfig:"level"`
+		Endpoint *url.URL      `gonfig:"endpoint"`
+	}
+	assert.Nil(t, c.Unmarshal(&cfg))
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.Equal(t, 250*time.Millisecond, cfg.Retry)
+	assert.Equal(t, 2022, cfg.Started.Year())
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), cfg.Addr)
+	assert.Equal(t, testLevel(1), *cfg.Level)
+	assert.Equal(t, "/api", cfg.Endpoint.Path)
+}
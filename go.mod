@@ -1,12 +1,16 @@
 module github.com/serdarkalayci/gonfig
 
-go 1.17
+go 1.18
 
 require (
-	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/BurntSushi/toml v1.2.1
+	github.com/hashicorp/hcl v1.0.0
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/objx v0.1.0 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
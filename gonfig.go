@@ -1,76 +1,140 @@
 package gonfig
 
 import (
-	"errors"
-	"os"
+	"fmt"
 	"strings"
+	"time"
 )
 
 type loadedSource struct {
-	items  map[string]interface{}
-	source ConfigSource
-	err    error
+	source   ConfigSource
+	provider Source
+	err      error
 }
 
 // Configuration is the collection of loaded configuration sources
 type Configuration struct {
-	sources  []loadedSource
+	sources   []loadedSource
+	defaults  memorySource
+	overrides memorySource
+	// strategies are the merge strategies of the arrays, keyed by the dotted paths without indices
+	strategies map[string]MergeStrategy
+	// setErrors are the errors of the keys that could not be set by SetDefault and Set
+	setErrors []SourceError
+	// DurationUnit is the unit of the numbers that are read as durations by GetDuration, e.g. time.Millisecond. Numbers are seconds if it's 0
+	DurationUnit time.Duration
+	// StrictBool makes GetBool and GetBoolArray accept only "true", "false", "1" and "0" as strings, instead of also accepting "yes", "on", "y", "t" and their negatives
+	StrictBool bool
+	// StrictArrays makes the array getters like GetIntArray return a *ConversionError for the first item that cannot be converted,
+	// with the index of the item in its key like "ports[1]", instead of skipping the item
+	StrictArrays bool
+	// TrimArrayElements makes the array getters trim the whitespace around the string items, e.g. " 443" in "[80, 443]" from the env
+	TrimArrayElements bool
+	// SkipEmptyArrayElements makes the array getters skip the string items that are empty or only whitespace, e.g. in "[80,,443]".
+	// Otherwise the empty items are "" in GetStringArray, and are conversion failures in the other array getters
+	SkipEmptyArrayElements bool
+	// HasError is true if any of the config sources could not be loaded, or a key could not be set by SetDefault or Set.
+	// Errors and Err return the details
 	HasError bool
 }
 
 // AddConfigSource adds multiple configuration sources to the collection.
 // Config sources will be evaluated in the order they are added.
 func (c Configuration) AddConfigSource(s ConfigSource) Configuration {
-	newSource := loadedSource{
+	loaded := loadedSource{
 		source: s,
 	}
-	switch s.Type {
-	case "json":
-		newSource.items, newSource.err = readJSON(s.FilePath)
-	case "yaml":
-		newSource.items, newSource.err = readYaml(s.FilePath)
+	loaded.provider, loaded.err = newSource(s)
+	if loaded.err == nil {
+		loaded.err = loaded.provider.Load()
 	}
-	if newSource.err != nil {
+	if loaded.err != nil {
 		c.HasError = true
 	}
-	c.sources = append(c.sources, newSource)
+	c.sources = appendSource(c.sources, loaded)
 	return c
 }
 
-func (c Configuration) findKey(key string) (interface{}, bool) {
-	var value interface{}
-	var found bool
+// AddSource adds a custom Source implementation to the collection.
+// Config sources will be evaluated in the order they are added, regardless of the method they are added with.
+func (c Configuration) AddSource(s Source) Configuration {
+	loaded := loadedSource{
+		provider: s,
+	}
+	loaded.err = s.Load()
+	if loaded.err != nil {
+		c.HasError = true
+	}
+	c.sources = appendSource(c.sources, loaded)
+	return c
+}
+
+// appendSource appends to a copy of the sources, so that the Configurations copied from the same base don't share their backing arrays
+func appendSource(sources []loadedSource, s loadedSource) []loadedSource {
+	return append(sources[:len(sources):len(sources)], s)
+}
+
+// layers returns the sources that are looked up, from the lowest priority to the highest.
+// The defaults come first, then the config sources that are loaded without errors in the order they are added, then the overrides.
+func (c Configuration) layers() []Source {
+	layers := make([]Source, 0, len(c.sources)+2)
+	layers = append(layers, c.defaults)
 	for _, loadedSource := range c.sources {
-		switch loadedSource.source.Type {
-		case "json", "yaml":
-			if val, fnd := loadedSource.items[key]; fnd {
-				value = val
-				found = fnd
-			}
-		case "env":
-			if val, fnd := os.LookupEnv(key); fnd {
-				value = val
-				if strings.HasPrefix(val, "[") && strings.HasSuffix(val, "]") { // We will assume the returned val is an array if it starts with "[" and ends with "]"
-					val = strings.TrimPrefix(val, "[")
-					val = strings.TrimSuffix(val, "]")
-					varr := strings.Split(val, ",")
-					value = varr
+		if loadedSource.err == nil {
+			layers = append(layers, loadedSource.provider)
+		}
+	}
+	return append(layers, c.overrides)
+}
+
+func (c Configuration) findKey(key string) (interface{}, bool) {
+	value, _, found := c.lookup(key)
+	return value, found
+}
+
+// lookup returns the value of the key from the source with the highest priority, and the name of that source.
+// If the value is a map, it's merged recursively with the maps of the key in the lower priority sources, and arrays are merged by their strategies.
+// The keys inside an array that has a merge strategy are looked up in the merged array.
+func (c Configuration) lookup(key string) (interface{}, string, bool) {
+	if ancestor, rest, ok := c.strategyAncestor(key); ok {
+		val, source, found := c.lookup(ancestor)
+		if !found {
+			return nil, "", false
+		}
+		val, found = lookupPath(val, rest)
+		return val, source, found
+	}
+	layers := c.layers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		val, found := layers[i].Lookup(key)
+		if !found {
+			continue
+		}
+		if composite(val) {
+			// The higher priority sources may assemble the key from their variables, see assembler
+			for j := len(layers) - 1; j > i; j-- {
+				if a, ok := layers[j].(assembler); ok {
+					if assembled, found := a.assemble(key); found {
+						return c.mergeLower(key, assembled, layers[:j]), layers[j].Name(), true
+					}
 				}
-				found = fnd
 			}
 		}
+		return c.mergeLower(key, val, layers[:i]), layers[i].Name(), true
 	}
-	return value, found
+	return nil, "", false
 }
 
-// GetInt returns the int value if the key is amongst the config sources and if the value is convertable to int
+// GetInt returns the int value if the key is amongst the config sources and if the value is convertable to int without overflowing.
+// Floats must be integral like for GetInt64.
 // Returns an error otherwise
 func (c Configuration) GetInt(key string) (int, error) {
-	val, found := c.findKey(key)
+	val, source, found := c.lookup(key)
 	if !found {
-		return 0, errors.New("The key is not found among config sources")
+		return 0, ErrKeyNotFound
 	}
-	return convertToInt(val)
+	converted, err := convertToInt(val)
+	return converted, withKey(err, key, source)
 }
 
 // GetIntOrDefault returns the int value if the key is amongst the config sources and if the value is convertable to int
@@ -82,12 +146,117 @@ func (c Configuration) GetIntOrDefault(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// GetInt64 returns the int64 value if the key is amongst the config sources and if the value is convertable to int64 without overflowing.
+// Floats must be integral, and strings can have 0x, 0o and 0b prefixes and _ digit separators like "0xff" or "1_000_000".
+// Returns an error otherwise
+func (c Configuration) GetInt64(key string) (int64, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToIntSize(val, 64)
+	return int64(converted), withKey(err, key, source)
+}
+
+// GetInt64OrDefault returns the int64 value if the key is amongst the config sources and if the value is convertable to int64 without overflowing
+// Returns the default value otherwise
+func (c Configuration) GetInt64OrDefault(key string, defaultValue int64) int64 {
+	if val, err := c.GetInt64(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetInt32 returns the int32 value if the key is amongst the config sources and if the value is convertable to int32 without overflowing.
+// See GetInt64 for the accepted values.
+// Returns an error otherwise
+func (c Configuration) GetInt32(key string) (int32, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToIntSize(val, 32)
+	return int32(converted), withKey(err, key, source)
+}
+
+// GetInt32OrDefault returns the int32 value if the key is amongst the config sources and if the value is convertable to int32 without overflowing
+// Returns the default value otherwise
+func (c Configuration) GetInt32OrDefault(key string, defaultValue int32) int32 {
+	if val, err := c.GetInt32(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetUint returns the uint value if the key is amongst the config sources and if the value is convertable to uint without overflowing.
+// See GetInt64 for the accepted values.
+// Returns an error otherwise
+func (c Configuration) GetUint(key string) (uint, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToUintSize(val, 0)
+	return uint(converted), withKey(err, key, source)
+}
+
+// GetUintOrDefault returns the uint value if the key is amongst the config sources and if the value is convertable to uint without overflowing
+// Returns the default value otherwise
+func (c Configuration) GetUintOrDefault(key string, defaultValue uint) uint {
+	if val, err := c.GetUint(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetUint64 returns the uint64 value if the key is amongst the config sources and if the value is convertable to uint64 without overflowing.
+// See GetInt64 for the accepted values.
+// Returns an error otherwise
+func (c Configuration) GetUint64(key string) (uint64, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToUintSize(val, 64)
+	return converted, withKey(err, key, source)
+}
+
+// GetUint64OrDefault returns the uint64 value if the key is amongst the config sources and if the value is convertable to uint64 without overflowing
+// Returns the default value otherwise
+func (c Configuration) GetUint64OrDefault(key string, defaultValue uint64) uint64 {
+	if val, err := c.GetUint64(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetUint32 returns the uint32 value if the key is amongst the config sources and if the value is convertable to uint32 without overflowing.
+// See GetInt64 for the accepted values.
+// Returns an error otherwise
+func (c Configuration) GetUint32(key string) (uint32, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToUintSize(val, 32)
+	return uint32(converted), withKey(err, key, source)
+}
+
+// GetUint32OrDefault returns the uint32 value if the key is amongst the config sources and if the value is convertable to uint32 without overflowing
+// Returns the default value otherwise
+func (c Configuration) GetUint32OrDefault(key string, defaultValue uint32) uint32 {
+	if val, err := c.GetUint32(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
 // GetString returns the string value if the key is amongst the config sources
 // Returns an error otherwise
 func (c Configuration) GetString(key string) (string, error) {
 	val, found := c.findKey(key)
 	if !found {
-		return "", errors.New("The key is not found among config sources")
+		return "", ErrKeyNotFound
 	}
 	return convertToString(val), nil
 }
@@ -104,11 +273,12 @@ func (c Configuration) GetStringOrDefault(key string, defaultValue string) strin
 // GetFloat returns the float value if the key is amongst the config sources and if the value is convertable to float
 // Returns an error otherwise
 func (c Configuration) GetFloat(key string) (float64, error) {
-	val, found := c.findKey(key)
+	val, source, found := c.lookup(key)
 	if !found {
-		return 0, errors.New("The key is not found among config sources")
+		return 0, ErrKeyNotFound
 	}
-	return convertToFloat(val)
+	converted, err := convertToFloat(val)
+	return converted, withKey(err, key, source)
 }
 
 // GetFloatOrDefault returns the float value if the key is amongst the config sources and if the value is convertable to float
@@ -120,14 +290,16 @@ func (c Configuration) GetFloatOrDefault(key string, defaultValue float64) float
 	return defaultValue
 }
 
-// GetBool returns the bool value if the key is amongst the config sources and if the value is convertable to bool
+// GetBool returns the bool value if the key is amongst the config sources and if the value is convertable to bool.
+// Strings like "true", "yes", "on", "y", "t" and "1" and their negatives are accepted case-insensitively, unless StrictBool is set.
 // Returns an error otherwise
 func (c Configuration) GetBool(key string) (bool, error) {
-	val, found := c.findKey(key)
+	val, source, found := c.lookup(key)
 	if !found {
-		return false, errors.New("The key is not found among config sources")
+		return false, ErrKeyNotFound
 	}
-	return convertToBool(val)
+	converted, err := c.convertToBool(val)
+	return converted, withKey(err, key, source)
 }
 
 // GetBoolOrDefault returns the bool value if the key is amongst the config sources and if the value is convertable to bool
@@ -139,32 +311,106 @@ func (c Configuration) GetBoolOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-// GetIntArray returns the []int value if the key is amongst the config sources.
-// It'll ignore if the items cannot be converted to int by skipping them
+// GetDuration returns the time.Duration value if the key is amongst the config sources and if the value is convertable to time.Duration.
+// Duration strings like "1m30s" are accepted as well as days and weeks like "2d" and "1w". Numbers are in DurationUnit.
 // Returns an error otherwise
-func (c Configuration) GetIntArray(key string) ([]int, error) {
-	val, found := c.findKey(key)
+func (c Configuration) GetDuration(key string) (time.Duration, error) {
+	val, source, found := c.lookup(key)
 	if !found {
-		return nil, errors.New("The key is not found among config sources")
-	}
-	arr := make([]int, 0)
-	switch val := val.(type) {
-	case []string:
-		for _, value := range val {
-			if newval, err := convertToInt(value); err == nil {
-				arr = append(arr, newval)
-			}
-		}
-	case []interface{}:
-		for _, value := range val {
-			if newval, err := convertToInt(value); err == nil {
-				arr = append(arr, newval)
-			}
-		}
-	default:
-		return nil, errors.New("The value is not an array or slice")
+		return 0, ErrKeyNotFound
 	}
-	return arr, nil
+	unit := c.DurationUnit
+	if unit == 0 {
+		unit = time.Second
+	}
+	converted, err := convertToDuration(val, unit)
+	return converted, withKey(err, key, source)
+}
+
+// GetDurationOrDefault returns the time.Duration value if the key is amongst the config sources and if the value is convertable to time.Duration
+// Returns the default value otherwise
+func (c Configuration) GetDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if val, err := c.GetDuration(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetTime returns the time.Time value if the key is amongst the config sources and if the value is convertable to time.Time.
+// RFC 3339 timestamps, dates like "2022-01-31", and the native dates of the TOML sources are accepted. Times without a time zone, including the TOML local ones, are in UTC.
+// Returns an error otherwise
+func (c Configuration) GetTime(key string) (time.Time, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return time.Time{}, ErrKeyNotFound
+	}
+	converted, err := convertToTime(val)
+	return converted, withKey(err, key, source)
+}
+
+// GetTimeOrDefault returns the time.Time value if the key is amongst the config sources and if the value is convertable to time.Time
+// Returns the default value otherwise
+func (c Configuration) GetTimeOrDefault(key string, defaultValue time.Time) time.Time {
+	if val, err := c.GetTime(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetByteSize returns the size in bytes if the key is amongst the config sources and if the value is convertable to a byte size.
+// Sizes like "512KB", "1.5GiB" or "10M" are accepted, SI units like "MB" are powers of 1000 and IEC units like "MiB" are powers of 1024.
+// Returns an error otherwise
+func (c Configuration) GetByteSize(key string) (uint64, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToByteSize(val)
+	return converted, withKey(err, key, source)
+}
+
+// GetByteSizeOrDefault returns the size in bytes if the key is amongst the config sources and if the value is convertable to a byte size
+// Returns the default value otherwise
+func (c Configuration) GetByteSizeOrDefault(key string, defaultValue uint64) uint64 {
+	if val, err := c.GetByteSize(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetRatio returns the float value if the key is amongst the config sources and if the value is a percentage like "75%" or a number like "0.75"
+// Returns an error otherwise
+func (c Configuration) GetRatio(key string) (float64, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	converted, err := convertToRatio(val)
+	return converted, withKey(err, key, source)
+}
+
+// GetRatioOrDefault returns the float value if the key is amongst the config sources and if the value is a percentage like "75%" or a number like "0.75"
+// Returns the default value otherwise
+func (c Configuration) GetRatioOrDefault(key string, defaultValue float64) float64 {
+	if val, err := c.GetRatio(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// convertToBool converts the value to bool in the mode set by StrictBool
+func (c Configuration) convertToBool(val interface{}) (bool, error) {
+	if c.StrictBool {
+		return convertToStrictBool(val)
+	}
+	return convertToBool(val)
+}
+
+// GetIntArray returns the []int value if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to int by skipping them, or returns a *ConversionError for the first of them if StrictArrays is set
+// Returns an error otherwise
+func (c Configuration) GetIntArray(key string) ([]int, error) {
+	return getArray(c, key, convertToInt)
 }
 
 // GetIntArrayOrDefault returns the []int value if the key is amongst the config sources.
@@ -178,24 +424,10 @@ func (c Configuration) GetIntArrayOrDefault(key string, defaultValue []int) []in
 }
 
 // GetStringArray returns the []string value if the key is amongst the config sources.
+// The items are trimmed if TrimArrayElements is set, and the empty ones are skipped if SkipEmptyArrayElements is set.
 // Returns an error otherwise
 func (c Configuration) GetStringArray(key string) ([]string, error) {
-	val, found := c.findKey(key)
-	if !found {
-		return nil, errors.New("The key is not found among config sources")
-	}
-	arr := make([]string, 0)
-	switch val := val.(type) {
-	case []string:
-		arr = val
-	case []interface{}:
-		for _, value := range val {
-			arr = append(arr, convertToString(value))
-		}
-	default:
-		return nil, errors.New("The value is not an array or slice")
-	}
-	return arr, nil
+	return getArray(c, key, stringElement)
 }
 
 // GetStringArrayOrDefault returns the []string value if the key is amongst the config sources.
@@ -208,31 +440,10 @@ func (c Configuration) GetStringArrayOrDefault(key string, defaultValue []string
 }
 
 // GetFloatArray returns the []float64 value if the key is amongst the config sources.
-// It'll ignore if the items cannot be converted to float64 by skipping them
+// It'll ignore if the items cannot be converted to float64 by skipping them, or returns a *ConversionError for the first of them if StrictArrays is set
 // Returns an error otherwise
 func (c Configuration) GetFloatArray(key string) ([]float64, error) {
-	val, found := c.findKey(key)
-	if !found {
-		return nil, errors.New("The key is not found among config sources")
-	}
-	arr := make([]float64, 0)
-	switch val := val.(type) {
-	case []string:
-		for _, value := range val {
-			if newval, err := convertToFloat(value); err == nil {
-				arr = append(arr, newval)
-			}
-		}
-	case []interface{}:
-		for _, value := range val {
-			if newval, err := convertToFloat(value); err == nil {
-				arr = append(arr, newval)
-			}
-		}
-	default:
-		return nil, errors.New("The value is not an array or slice")
-	}
-	return arr, nil
+	return getArray(c, key, convertToFloat)
 }
 
 // GetFloatArrayOrDefault returns the []float64 value if the key is amongst the config sources.
@@ -244,3 +455,102 @@ func (c Configuration) GetFloatArrayOrDefault(key string, defaultValue []float64
 	}
 	return defaultValue
 }
+
+// GetByteSizeArray returns the sizes in bytes if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to byte sizes by skipping them, or returns a *ConversionError for the first of them if StrictArrays is set
+// Returns an error otherwise
+func (c Configuration) GetByteSizeArray(key string) ([]uint64, error) {
+	return getArray(c, key, convertToByteSize)
+}
+
+// GetByteSizeArrayOrDefault returns the sizes in bytes if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to byte sizes by skipping them
+// Returns the default value otherwise
+func (c Configuration) GetByteSizeArrayOrDefault(key string, defaultValue []uint64) []uint64 {
+	if val, err := c.GetByteSizeArray(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetRatioArray returns the ratios if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to ratios by skipping them, or returns a *ConversionError for the first of them if StrictArrays is set
+// Returns an error otherwise
+func (c Configuration) GetRatioArray(key string) ([]float64, error) {
+	return getArray(c, key, convertToRatio)
+}
+
+// GetRatioArrayOrDefault returns the ratios if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to ratios by skipping them
+// Returns the default value otherwise
+func (c Configuration) GetRatioArrayOrDefault(key string, defaultValue []float64) []float64 {
+	if val, err := c.GetRatioArray(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// GetBoolArray returns the []bool value if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to bool by skipping them, or returns a *ConversionError for the first of them if StrictArrays is set
+// Returns an error otherwise
+func (c Configuration) GetBoolArray(key string) ([]bool, error) {
+	return getArray(c, key, c.convertToBool)
+}
+
+// GetBoolArrayOrDefault returns the []bool value if the key is amongst the config sources.
+// It'll ignore if the items cannot be converted to bool by skipping them
+// Returns the default value otherwise
+func (c Configuration) GetBoolArrayOrDefault(key string, defaultValue []bool) []bool {
+	if val, err := c.GetBoolArray(key); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
+// getArray converts the items of the array value of the key with the given converter, by the array options of the Configuration
+func getArray[T any](c Configuration, key string, convert func(interface{}) (T, error)) ([]T, error) {
+	val, source, found := c.lookup(key)
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	items, ok := sourceArray(val, source)
+	if !ok {
+		return nil, ErrNotArray
+	}
+	arr := make([]T, 0, len(items))
+	for i, item := range items {
+		item, ok := c.arrayElement(item)
+		if !ok {
+			continue
+		}
+		converted, err := convert(item)
+		if err != nil {
+			if c.StrictArrays {
+				return nil, withKey(err, fmt.Sprintf("%s[%d]", key, i), source)
+			}
+			continue
+		}
+		arr = append(arr, converted)
+	}
+	return arr, nil
+}
+
+// arrayElement trims an array item if TrimArrayElements is set, and returns false if it's empty and SkipEmptyArrayElements is set
+func (c Configuration) arrayElement(item interface{}) (interface{}, bool) {
+	s, ok := item.(string)
+	if !ok {
+		return item, true
+	}
+	if c.SkipEmptyArrayElements && strings.TrimSpace(s) == "" {
+		return nil, false
+	}
+	if c.TrimArrayElements {
+		return strings.TrimSpace(s), true
+	}
+	return s, true
+}
+
+// stringElement converts an array item to string, which never fails
+func stringElement(val interface{}) (string, error) {
+	return convertToString(val), nil
+}
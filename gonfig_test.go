@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -92,7 +93,7 @@ func Test_AddConfigSource_Env(t *testing.T) {
 	// Let's try to find an array key that's there
 	val, found = c.findKey("arrkey1")
 	assert.Equal(t, true, found)
-	assert.Equal(t, []string{"\"val1\"", "\"val2\"", "\"val3\""}, val)
+	assert.Equal(t, []interface{}{"val1", "val2", "val3"}, val)
 }
 
 func Test_GetInt(t *testing.T) {
@@ -437,3 +438,330 @@ func Test_GetFloatArrayOrDefault(t *testing.T) {
 	val = c.GetFloatArrayOrDefault("key2", defArray)
 	assert.Equal(t, defArray, val)
 }
+
+func Test_AddConfigSource_NoAliasing(t *testing.T) {
+	var base Configuration
+	for i := 0; i < 3; i++ { // 3 sources leave spare capacity in the backing array
+		base = base.AddSource(NewMapSource(map[string]interface{}{"key1": "base"}))
+	}
+	c1 := base.AddSource(NewMapSource(map[string]interface{}{"key1": "c1"}))
+	c2 := base.AddSource(NewMapSource(map[string]interface{}{"key1": "c2"}))
+	assert.Equal(t, "c1", c1.GetStringOrDefault("key1", ""))
+	assert.Equal(t, "c2", c2.GetStringOrDefault("key1", ""))
+	assert.Equal(t, "base", base.GetStringOrDefault("key1", ""))
+}
+
+func Test_GetDuration(t *testing.T) {
+	var c Configuration
+	mockFile("{\"timeout\":\"1m30s\", \"ttl\":\"2d\", \"retry\":5, \"bad\":\"soon\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	val, err := c.GetDuration("timeout")
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, val)
+	val, err = c.GetDuration("ttl")
+	assert.Nil(t, err)
+	assert.Equal(t, 48*time.Hour, val)
+	// Numbers are seconds unless DurationUnit is set
+	val, err = c.GetDuration("retry")
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, val)
+	c.DurationUnit = time.Millisecond
+	val, err = c.GetDuration("retry")
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Millisecond, val)
+	// DurationUnit is kept by the methods that return a new Configuration
+	c = c.SetDefault("other", 1)
+	assert.Equal(t, time.Millisecond, c.GetDurationOrDefault("other", 0))
+	_, err = c.GetDuration("bad")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "bad", convErr.Key)
+	_, err = c.GetDuration("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, time.Minute, c.GetDurationOrDefault("bad", time.Minute))
+	assert.Equal(t, time.Minute, c.GetDurationOrDefault("missing", time.Minute))
+}
+
+func Test_GetTime(t *testing.T) {
+	var c Configuration
+	mockFile("released = 2022-03-04T05:06:07Z\nbirthday = 1979-05-27\nalarm = 2022-03-04T07:30:00\ncreated = \"2022-03-04\"\nbad = \"yesterday\"", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeTOML,
+		FilePath: "testing.toml",
+	})
+	mockFile("updated: 2022-03-04T05:06:07+02:00", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "testing.yaml",
+	})
+	val, err := c.GetTime("released")
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC).Equal(val))
+	// TOML local dates and times are in UTC like the strings without a time zone
+	val, err = c.GetTime("birthday")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC), val)
+	val, err = c.GetTime("alarm")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2022, 3, 4, 7, 30, 0, 0, time.UTC), val)
+	val, err = c.GetTime("created")
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC).Equal(val))
+	val, err = c.GetTime("updated")
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2022, 3, 4, 3, 6, 7, 0, time.UTC).Equal(val))
+	_, err = c.GetTime("bad")
+	assert.ErrorIs(t, err, errUnknownValue)
+	_, err = c.GetTime("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, fallback, c.GetTimeOrDefault("bad", fallback))
+}
+
+func Test_GetByteSize_GetRatio(t *testing.T) {
+	var c Configuration
+	mockFile("{\"cache\":\"512MiB\", \"sizes\":[\"1KB\", \"bad\", 2048], \"ratio\":\"75%\", \"ratios\":[\"50%\", 0.25, \"bad\"]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("BUFFER_SIZE", "10M")
+	os.Setenv("BUFFER_RATIOS", "[10%,0.2]")
+	defer os.Unsetenv("BUFFER_SIZE")
+	defer os.Unsetenv("BUFFER_RATIOS")
+	size, err := c.GetByteSize("cache")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(512*1024*1024), size)
+	size, err = c.GetByteSize("buffer.size")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10000000), size)
+	_, err = c.GetByteSize("ratio")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ratio", convErr.Key)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+	_, err = c.GetByteSize("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, uint64(1), c.GetByteSizeOrDefault("ratio", 1))
+	sizes, err := c.GetByteSizeArray("sizes")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1000, 2048}, sizes)
+	_, err = c.GetByteSizeArray("cache")
+	assert.ErrorIs(t, err, ErrNotArray)
+	assert.Equal(t, []uint64{1}, c.GetByteSizeArrayOrDefault("missing", []uint64{1}))
+
+	ratio, err := c.GetRatio("ratio")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.75, ratio)
+	assert.Equal(t, 0.5, c.GetRatioOrDefault("cache", 0.5))
+	ratios, err := c.GetRatioArray("ratios")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.5, 0.25}, ratios)
+	ratios, err = c.GetRatioArray("buffer.ratios")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.1, 0.2}, ratios)
+	assert.Equal(t, []float64{1}, c.GetRatioArrayOrDefault("ratio", []float64{1}))
+}
+
+func Test_GetBool_Strings(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("FEATURE_X", "true")
+	os.Setenv("FEATURE_Y", "off")
+	defer os.Unsetenv("FEATURE_X")
+	defer os.Unsetenv("FEATURE_Y")
+	val, err := c.GetBool("feature.x")
+	assert.Nil(t, err)
+	assert.Equal(t, true, val)
+	val, err = c.GetBool("feature.y")
+	assert.Nil(t, err)
+	assert.Equal(t, false, val)
+	c.StrictBool = true
+	val, err = c.GetBool("feature.x")
+	assert.Nil(t, err)
+	assert.Equal(t, true, val)
+	_, err = c.GetBool("feature.y")
+	assert.ErrorIs(t, err, errUnknownValue)
+	assert.Equal(t, true, c.GetBoolOrDefault("feature.y", true))
+}
+
+func Test_GetBoolArray(t *testing.T) {
+	var c Configuration
+	mockFile("{\"flags\":[true, \"no\", 1, \"on\", \"maybe\", 0], \"single\":true}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("ENV_FLAGS", "[yes,false,x]")
+	defer os.Unsetenv("ENV_FLAGS")
+	val, err := c.GetBoolArray("flags")
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, false, true, true, false}, val)
+	val, err = c.GetBoolArray("env.flags")
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, false}, val)
+	_, err = c.GetBoolArray("single")
+	assert.ErrorIs(t, err, ErrNotArray)
+	_, err = c.GetBoolArray("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	c.StrictBool = true
+	val, err = c.GetBoolArray("flags")
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{true, true, false}, val)
+	assert.Equal(t, []bool{true}, c.GetBoolArrayOrDefault("single", []bool{true}))
+}
+
+func Test_GetSizedInts(t *testing.T) {
+	var c Configuration
+	mockFile("{\"big\":\"9007199254740993\", \"huge\":\"18446744073709551615\", \"mask\":\"0xFF_FF\", \"ratio\":3.9, \"negative\":-1, \"far\":1e20, \"ratios\":[1, 3.9]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	i64, err := c.GetInt64("big")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9007199254740993), i64)
+	_, err = c.GetInt64("huge")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "huge", convErr.Key)
+	assert.Equal(t, "int64", convErr.Type)
+	assert.ErrorIs(t, err, errOverflow)
+	u64, err := c.GetUint64("huge")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(18446744073709551615), u64)
+	i32, err := c.GetInt32("mask")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(65535), i32)
+	_, err = c.GetInt32("big")
+	assert.ErrorIs(t, err, errOverflow)
+	u32, err := c.GetUint32("mask")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(65535), u32)
+	_, err = c.GetInt64("ratio")
+	assert.ErrorIs(t, err, errNotIntegral)
+	assert.EqualError(t, err, "Cannot convert 3.9 (float64) to int64 for the key ratio from json:testing.json: The value is not an integer")
+	// GetInt converts like the sized getters instead of truncating
+	_, err = c.GetInt("ratio")
+	assert.ErrorIs(t, err, errNotIntegral)
+	_, err = c.GetInt("far")
+	assert.ErrorIs(t, err, errOverflow)
+	ints, err := c.GetIntArray("ratios")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, ints)
+	_, err = c.GetUint("negative")
+	assert.ErrorIs(t, err, errOverflow)
+	_, err = c.GetUint64("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int64(7), c.GetInt64OrDefault("ratio", 7))
+	assert.Equal(t, int32(7), c.GetInt32OrDefault("big", 7))
+	assert.Equal(t, uint(7), c.GetUintOrDefault("negative", 7))
+	assert.Equal(t, uint64(7), c.GetUint64OrDefault("missing", 7))
+	assert.Equal(t, uint32(65535), c.GetUint32OrDefault("mask", 7))
+	// Unmarshal and Get use the same checks
+	var cfg struct {
+		Ratio int64  `gonfig:"ratio"`
+		Mask  uint16 `gonfig:"mask"`
+	}
+	err = c.Unmarshal(&cfg)
+	var unmarshalErr *UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 1, len(unmarshalErr.Fields))
+	assert.ErrorIs(t, unmarshalErr.Fields[0].Err, errNotIntegral)
+	assert.Equal(t, uint16(65535), cfg.Mask)
+	_, err = Get[uint8](c, "mask")
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "uint8", convErr.Type)
+	assert.ErrorIs(t, err, errOverflow)
+}
+
+func Test_GetArray_Strict(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("ports", "[80,abc,443]")
+	os.Setenv("ratios", "[0.5,half]")
+	defer os.Unsetenv("ports")
+	defer os.Unsetenv("ratios")
+	ports, err := c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	c.StrictArrays = true
+	_, err = c.GetIntArray("ports")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ports[1]", convErr.Key)
+	assert.Equal(t, "abc", convErr.Value)
+	assert.Equal(t, "env", convErr.Source)
+	assert.EqualError(t, err, "Cannot convert abc (string) to int for the key ports[1] from env: strconv.ParseInt: parsing \"abc\": invalid syntax")
+	_, err = c.GetFloatArray("ratios")
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ratios[1]", convErr.Key)
+	_, err = c.GetBoolArray("ports")
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ports[0]", convErr.Key)
+	assert.Equal(t, []int{1}, c.GetIntArrayOrDefault("ports", []int{1}))
+	strs, err := c.GetStringArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"80", "abc", "443"}, strs)
+}
+
+func Test_GetArray_Elements(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("ports", "[80, ,443 ,]")
+	defer os.Unsetenv("ports")
+	ports, err := c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	strs, err := c.GetStringArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"80", " ", "443 ", ""}, strs)
+	os.Setenv("ratios", "[ 0.5, 1.5 ]")
+	defer os.Unsetenv("ratios")
+	ratios, err := c.GetFloatArray("ratios")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0.5, 1.5}, ratios)
+	c.TrimArrayElements = true
+	ports, err = c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	strs, err = c.GetStringArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"80", "", "443", ""}, strs)
+	c.StrictArrays = true
+	_, err = c.GetIntArray("ports")
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ports[1]", convErr.Key)
+	c.SkipEmptyArrayElements = true
+	ports, err = c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+	strs, err = c.GetStringArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"80", "443"}, strs)
+	// Get and Unmarshal use the same options
+	ints, err := Get[[]int](c, "ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ints)
+	var cfg struct {
+		Ports []uint16 `gonfig:"ports"`
+	}
+	assert.Nil(t, c.Unmarshal(&cfg))
+	assert.Equal(t, []uint16{80, 443}, cfg.Ports)
+}
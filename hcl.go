@@ -0,0 +1,107 @@
+package gonfig
+
+import (
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+func decodeHCL(data []byte) (map[string]interface{}, error) {
+	file, err := hcl.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		var output map[string]interface{}
+		if err := hcl.DecodeObject(&output, file.Node); err != nil {
+			return nil, err
+		}
+		return normalizeHCL(output).(map[string]interface{}), nil
+	}
+	return decodeHCLObject(list)
+}
+
+// hclBlocks collects the bodies of the blocks with the same name and labels while an object is decoded
+type hclBlocks []interface{}
+
+// decodeHCLObject converts the items of a file or a block body to the shape of the other sources, telling the labels
+// of the blocks apart from the nested blocks by the keys of the items in the AST.
+// A single block becomes a nested map, labeled blocks become maps keyed by their labels and repeated blocks become arrays.
+func decodeHCLObject(list *ast.ObjectList) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	for _, item := range list.Items {
+		keys := make([]string, len(item.Keys))
+		for i, key := range item.Keys {
+			keys[i] = convertToString(key.Token.Value())
+		}
+		node := output
+		for _, key := range keys[:len(keys)-1] { // Labels of a block like `service "web" {}`
+			child, ok := node[key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[key] = child
+			}
+			node = child
+		}
+		name := keys[len(keys)-1]
+		object, isBlock := item.Val.(*ast.ObjectType)
+		if !isBlock {
+			var val interface{}
+			if err := hcl.DecodeObject(&val, item.Val); err != nil {
+				return nil, err
+			}
+			node[name] = normalizeHCL(val)
+			continue
+		}
+		body, err := decodeHCLObject(object.List)
+		if err != nil {
+			return nil, err
+		}
+		blocks, _ := node[name].(hclBlocks)
+		node[name] = append(blocks, body)
+	}
+	return collapseHCLBlocks(output).(map[string]interface{}), nil
+}
+
+// collapseHCLBlocks replaces the collected blocks with the single block, or with an array of the repeated blocks
+func collapseHCLBlocks(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = collapseHCLBlocks(item)
+		}
+		return v
+	case hclBlocks:
+		if len(v) == 1 {
+			return v[0]
+		}
+		return []interface{}(v)
+	}
+	return value
+}
+
+// normalizeHCL converts the objects in the values, which the HCL decoder returns as []map[string]interface{}, to nested maps
+func normalizeHCL(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeHCL(item)
+		}
+		return v
+	case []map[string]interface{}:
+		if len(v) == 1 {
+			return normalizeHCL(v[0])
+		}
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = normalizeHCL(item)
+		}
+		return arr
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeHCL(item)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+package gonfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeHCL_Success(t *testing.T) {
+	payload := `
+name = "svc"
+ports = [80, 443]
+database {
+  host = "localhost"
+  port = 5432
+}
+service "web" {
+  port = 80
+}
+service "api" {
+  port = 8080
+}
+listener {
+  port = 1
+}
+listener {
+  port = 2
+}
+motd = <<EOT
+hello
+world
+EOT
+`
+	result, err := decodeHCL([]byte(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "svc",
+		"ports":    []interface{}{80, 443},
+		"database": map[string]interface{}{"host": "localhost", "port": 5432},
+		"service": map[string]interface{}{
+			"web": map[string]interface{}{"port": 80},
+			"api": map[string]interface{}{"port": 8080},
+		},
+		"listener": []interface{}{
+			map[string]interface{}{"port": 1},
+			map[string]interface{}{"port": 2},
+		},
+		"motd": "hello\nworld\n",
+	}, result)
+}
+
+func Test_decodeHCL_Blocks(t *testing.T) {
+	// Repeated blocks holding different nested blocks are not mistaken for labeled blocks
+	payload := `
+server {
+  tls {
+    enabled = true
+  }
+}
+server {
+  auth {
+    mode = "x"
+  }
+}
+region "eu" "west" {
+  zone = 1
+}
+region "eu" "north" {
+  zone = 2
+}
+options = [{ retries = 3 }]
+`
+	result, err := decodeHCL([]byte(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"server": []interface{}{
+			map[string]interface{}{"tls": map[string]interface{}{"enabled": true}},
+			map[string]interface{}{"auth": map[string]interface{}{"mode": "x"}},
+		},
+		"region": map[string]interface{}{
+			"eu": map[string]interface{}{
+				"west":  map[string]interface{}{"zone": 1},
+				"north": map[string]interface{}{"zone": 2},
+			},
+		},
+		"options": []interface{}{map[string]interface{}{"retries": 3}},
+	}, result)
+}
+
+func Test_decodeHCL_UnmarshalError(t *testing.T) {
+	result, err := decodeHCL([]byte("database {\n  host = \"localhost\"\n"))
+	assert.NotNil(t, err)
+	assert.Nil(t, result)
+}
+
+func Test_AddConfigSource_HCL(t *testing.T) {
+	var c Configuration
+	mockFile("service \"web\" {\n  port = 80\n}\nlistener {\n  port = 1\n}\nlistener {\n  port = 2\n}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeHCL,
+		FilePath: "testing.hcl",
+	})
+	assert.Equal(t, false, c.HasError)
+	port, err := c.GetInt("service.web.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 80, port)
+	port, err = c.GetInt("listener[1].port")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, port)
+}
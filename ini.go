@@ -0,0 +1,85 @@
+package gonfig
+
+import (
+	"strings"
+)
+
+// decodeINI parses the contents of an INI file.
+// Keys before the first [section] header are top level keys, the others are nested under their section.
+// Dotted section names like [database.replica] are nested further. Lines starting with ";" or "#" are comments.
+// Values may be quoted, and keys ending with "[]" are collected into arrays.
+func decodeINI(data []byte) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	section := output
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, &ParseError{Format: "ini", Line: lineNo, Msg: "unterminated section header"}
+			}
+			name := strings.TrimSpace(line[1:end])
+			if name == "" {
+				return nil, &ParseError{Format: "ini", Line: lineNo, Msg: "empty section name"}
+			}
+			section = iniSection(output, name)
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 {
+			return nil, &ParseError{Format: "ini", Line: lineNo, Msg: "missing '=' after the key"}
+		}
+		key := strings.TrimSpace(line[:sep])
+		if key == "" {
+			return nil, &ParseError{Format: "ini", Line: lineNo, Msg: "empty key"}
+		}
+		value := iniValue(strings.TrimSpace(line[sep+1:]))
+		if strings.HasSuffix(key, "[]") {
+			key = strings.TrimSuffix(key, "[]")
+			arr, _ := section[key].([]interface{})
+			section[key] = append(arr, value)
+			continue
+		}
+		section[key] = value
+	}
+	return output, nil
+}
+
+// iniSection returns the map of the named section, creating it and its parents as needed
+func iniSection(output map[string]interface{}, name string) map[string]interface{} {
+	section := output
+	for _, part := range strings.Split(name, ".") {
+		part = strings.TrimSpace(part)
+		child, ok := section[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			section[part] = child
+		}
+		section = child
+	}
+	return section
+}
+
+// iniValue removes the inline comment after a value and the quotes around it.
+// Comment markers inside the quotes are kept, e.g. "a ;b" ; comment is a ;b.
+func iniValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			rest := strings.TrimSpace(value[end+2:])
+			if rest == "" || rest[0] == ';' || rest[0] == '#' {
+				return value[1 : end+1]
+			}
+		}
+	}
+	for _, marker := range []string{" ;", " #"} {
+		if comment := strings.Index(value, marker); comment >= 0 {
+			value = value[:comment]
+		}
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,57 @@
+package gonfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeINI_Success(t *testing.T) {
+	payload := "; comment\nname = svc\n\n[database]\nhost = localhost ; inline comment\nport: 5432\npassword = \"p;a#ss\"\nuser = 'admin' ; quoted with a comment\n\n[database.replica]\nhost=replica\n\n[servers]\nhosts[] = a\nhosts[] = b\n"
+	result, err := decodeINI([]byte(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": "svc",
+		"database": map[string]interface{}{
+			"host":     "localhost",
+			"port":     "5432",
+			"password": "p;a#ss",
+			"user":     "admin",
+			"replica":  map[string]interface{}{"host": "replica"},
+		},
+		"servers": map[string]interface{}{
+			"hosts": []interface{}{"a", "b"},
+		},
+	}, result)
+}
+
+func Test_decodeINI_Errors(t *testing.T) {
+	_, err := decodeINI([]byte("[database\nhost = localhost"))
+	assert.EqualError(t, err, "ini: line 1: unterminated section header")
+	_, err = decodeINI([]byte("[]"))
+	assert.EqualError(t, err, "ini: line 1: empty section name")
+	_, err = decodeINI([]byte("[database]\nhost"))
+	assert.EqualError(t, err, "ini: line 2: missing '=' after the key")
+	_, err = decodeINI([]byte("= value"))
+	assert.EqualError(t, err, "ini: line 1: empty key")
+}
+
+func Test_AddConfigSource_INI(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"localhost\",\"port\":5432}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	mockFile("[database]\nport = 6543", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeINI,
+		FilePath: "testing.ini",
+	})
+	port, err := c.GetInt("database.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 6543, port)
+	host, err := c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", host)
+}
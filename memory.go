@@ -0,0 +1,106 @@
+package gonfig
+
+import "fmt"
+
+// memorySource is a Source that holds its items in memory
+type memorySource struct {
+	name  string
+	items map[string]interface{}
+}
+
+// NewMapSource creates a Source from the given items. Nested maps, arrays and dotted keys are looked up like the file based sources.
+// The items are copied, so modifying the map later doesn't change the source.
+func NewMapSource(items map[string]interface{}) Source {
+	return memorySource{name: "map", items: copyMap(items)}
+}
+
+func (s memorySource) Name() string {
+	return s.name
+}
+
+func (s memorySource) Load() error {
+	return nil
+}
+
+func (s memorySource) Lookup(key string) (interface{}, bool) {
+	return lookupPath(s.items, key)
+}
+
+func (s memorySource) Settings() map[string]interface{} {
+	return s.items
+}
+
+// set returns a copy of the source with the dotted key set to the given value.
+// The source is returned unchanged with the error of setPath if the key has an array index that's too large.
+func (s memorySource) set(key string, value interface{}) (memorySource, error) {
+	items := copyMap(s.items)
+	if items == nil {
+		items = make(map[string]interface{})
+	}
+	if err := setPath(items, key, copyValue(value)); err != nil {
+		return s, fmt.Errorf("%s: %w", key, err)
+	}
+	return memorySource{name: s.name, items: items}, nil
+}
+
+// SetDefault sets the default value of a key. Defaults have the lowest priority, so they're used only if none of the config sources have the key.
+// Like AddConfigSource, it returns the updated Configuration. If the key cannot be set, e.g. because it has an array index that's too large,
+// HasError is set and Errors returns the error of the "default" source.
+func (c Configuration) SetDefault(key string, value interface{}) Configuration {
+	var err error
+	c.defaults, err = memorySource{name: "default", items: c.defaults.items}.set(key, value)
+	return c.addSetError(c.defaults, err)
+}
+
+// Set overrides the value of a key. Overrides have the highest priority, so they're used regardless of the config sources.
+// Like AddConfigSource, it returns the updated Configuration. If the key cannot be set, e.g. because it has an array index that's too large,
+// HasError is set and Errors returns the error of the "override" source.
+func (c Configuration) Set(key string, value interface{}) Configuration {
+	var err error
+	c.overrides, err = memorySource{name: "override", items: c.overrides.items}.set(key, value)
+	return c.addSetError(c.overrides, err)
+}
+
+// addSetError records the error of setting a key of the defaults or the overrides, if any
+func (c Configuration) addSetError(s memorySource, err error) Configuration {
+	if err == nil {
+		return c
+	}
+	c.HasError = true
+	c.setErrors = append(c.setErrors[:len(c.setErrors):len(c.setErrors)], SourceError{Source: s.name, Err: err})
+	return c
+}
+
+// copyMap returns a deep copy of the nested maps and arrays of the given map
+func copyMap(items map[string]interface{}) map[string]interface{} {
+	if items == nil {
+		return nil
+	}
+	return copyValue(items).(map[string]interface{})
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = copyValue(item)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			m[key] = copyValue(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = copyValue(item)
+		}
+		return arr
+	case []string:
+		return append([]string(nil), v...)
+	}
+	return value
+}
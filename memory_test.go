@@ -0,0 +1,102 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewMapSource(t *testing.T) {
+	items := map[string]interface{}{
+		"database": map[string]interface{}{"host": "localhost"},
+		"ports":    []interface{}{80, 443},
+	}
+	var c Configuration
+	c = c.AddSource(NewMapSource(items))
+	items["database"].(map[string]interface{})["host"] = "changed" // The source keeps its own copy
+	assert.Equal(t, "map", c.sources[0].provider.Name())
+	host, err := c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", host)
+	ports, err := c.GetIntArray("ports")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+}
+
+func Test_SetDefault_Set(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("http.port", 8080)
+	c = c.SetDefault("http.host", "0.0.0.0")
+	c = c.SetDefault("log.level", "info")
+	mockFile("{\"http\":{\"port\":9090}, \"log\":{\"level\":\"debug\"}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("LOG_LEVEL", "warn")
+	defer os.Unsetenv("LOG_LEVEL")
+	// Config sources override the defaults
+	port, err := c.GetInt("http.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 9090, port)
+	// Defaults are used when none of the sources have the key
+	host, err := c.GetString("http.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0.0.0", host)
+	level, err := c.GetString("log.level")
+	assert.Nil(t, err)
+	assert.Equal(t, "warn", level)
+	// Overrides have the highest priority, even if they're set before adding the sources
+	overridden := c.Set("log.level", "error")
+	level, err = overridden.GetString("log.level")
+	assert.Nil(t, err)
+	assert.Equal(t, "error", level)
+	// The original Configuration is not changed
+	level, err = c.GetString("log.level")
+	assert.Nil(t, err)
+	assert.Equal(t, "warn", level)
+}
+
+func Test_SetDefault_Set_Errors(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("a[70000]", 1)
+	assert.Equal(t, true, c.HasError)
+	errs := c.Errors()
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "default", errs[0].Source)
+	assert.ErrorIs(t, errs[0], errIndexTooLarge)
+	assert.EqualError(t, errs[0], "default: a[70000]: The array index is too large")
+	_, err := c.GetInt("a[70000]")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	overridden := c.Set("b[70000]", 1)
+	assert.ErrorIs(t, overridden.Err(), errIndexTooLarge)
+	assert.Equal(t, 2, len(overridden.Errors()))
+	assert.Equal(t, "override", overridden.Errors()[1].Source)
+	// The original Configuration is not changed
+	assert.Equal(t, 1, len(c.Errors()))
+	var valid Configuration
+	valid = valid.Set("a[1]", 1)
+	assert.Equal(t, false, valid.HasError)
+	assert.Nil(t, valid.Err())
+}
+
+func Test_copyValue(t *testing.T) {
+	original := map[string]interface{}{
+		"nested": map[interface{}]interface{}{"key": "value"},
+		"arr":    []interface{}{map[string]interface{}{"key": "value"}},
+		"strs":   []string{"a"},
+	}
+	copied := copyMap(original)
+	assert.Equal(t, original, copied)
+	copied["nested"].(map[interface{}]interface{})["key"] = "changed"
+	copied["arr"].([]interface{})[0].(map[string]interface{})["key"] = "changed"
+	copied["strs"].([]string)[0] = "changed"
+	assert.Equal(t, "value", original["nested"].(map[interface{}]interface{})["key"])
+	assert.Equal(t, "value", original["arr"].([]interface{})[0].(map[string]interface{})["key"])
+	assert.Equal(t, "a", original["strs"].([]string)[0])
+	assert.Nil(t, copyMap(nil))
+}
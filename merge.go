@@ -0,0 +1,233 @@
+package gonfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type arrayMergeMode int
+
+const (
+	arrayReplace arrayMergeMode = iota
+	arrayAppend
+	arrayMergeByIndex
+	arrayMergeByID
+)
+
+// MergeStrategy defines how the arrays of a key are merged when more than one config source has the key
+type MergeStrategy struct {
+	mode    arrayMergeMode
+	idField string
+}
+
+var (
+	// MergeReplace uses the array of the source with the highest priority as it is. It's the strategy of the arrays that have no strategy set
+	MergeReplace = MergeStrategy{mode: arrayReplace}
+	// MergeAppend appends the items of the higher priority arrays to the items of the lower priority arrays
+	MergeAppend = MergeStrategy{mode: arrayAppend}
+	// MergeByIndex merges the items at the same index, so that a higher priority array can override some of the items of a lower priority array
+	MergeByIndex = MergeStrategy{mode: arrayMergeByIndex}
+)
+
+// MergeByID merges the items that are maps with the same value of the given field, e.g. MergeByID("name").
+// The items of the higher priority arrays with a new id, or without the field, are appended.
+func MergeByID(field string) MergeStrategy {
+	return MergeStrategy{mode: arrayMergeByID, idField: field}
+}
+
+// SetMergeStrategy sets how the arrays of the key are merged across the config sources.
+// The key is a dotted path without array indices, e.g. "servers" or "servers.tags" for the tags of every server.
+// When a strategy is set, the keys inside the array, e.g. "servers[1].port", are looked up in the merged array.
+// Like AddConfigSource, it returns the updated Configuration.
+func (c Configuration) SetMergeStrategy(key string, strategy MergeStrategy) Configuration {
+	strategies := make(map[string]MergeStrategy, len(c.strategies)+1)
+	for k, s := range c.strategies {
+		strategies[k] = s
+	}
+	strategies[key] = strategy
+	c.strategies = strategies
+	return c
+}
+
+// mergeLower merges the value of the key in a source with the values of the key in the lower priority layers.
+// Maps are merged recursively, and arrays are merged if a strategy other than MergeReplace is set for the key.
+// The merge stops at the first lower priority value that cannot be merged, as that value is replaced by the higher priority ones.
+func (c Configuration) mergeLower(key string, value interface{}, lower []Source) interface{} {
+	if !c.mergeable(key, value) {
+		return value
+	}
+	var values []interface{}
+	for i := len(lower) - 1; i >= 0; i-- {
+		val, found := lookupComposite(lower[i], key)
+		if !found {
+			continue
+		}
+		if !sameKind(value, val) {
+			break
+		}
+		values = append(values, val)
+	}
+	if len(values) == 0 {
+		return value
+	}
+	merged := values[len(values)-1]
+	for i := len(values) - 2; i >= 0; i-- {
+		merged = c.mergeValues(key, merged, values[i])
+	}
+	return c.mergeValues(key, merged, value)
+}
+
+// mergeable returns true if the value of the key is merged with the lower priority values
+func (c Configuration) mergeable(key string, value interface{}) bool {
+	if _, ok := toMap(value); ok {
+		return true
+	}
+	if _, ok := toArray(value); ok {
+		return c.strategyFor(key).mode != arrayReplace
+	}
+	return false
+}
+
+// mergeValues merges a higher priority value of the key into a lower priority one without modifying either of them
+func (c Configuration) mergeValues(key string, lower interface{}, higher interface{}) interface{} {
+	if lowerMap, ok := toMap(lower); ok {
+		if higherMap, ok := toMap(higher); ok {
+			merged := make(map[string]interface{}, len(lowerMap)+len(higherMap))
+			for k, val := range lowerMap {
+				merged[k] = val
+			}
+			for k, val := range higherMap {
+				if lowerVal, found := merged[k]; found {
+					val = c.mergeValues(joinKey(key, k), lowerVal, val)
+				}
+				merged[k] = val
+			}
+			return merged
+		}
+		return higher
+	}
+	lowerArr, ok := toArray(lower)
+	if !ok {
+		return higher
+	}
+	higherArr, ok := toArray(higher)
+	if !ok {
+		return higher
+	}
+	switch strategy := c.strategyFor(key); strategy.mode {
+	case arrayAppend:
+		return append(append(make([]interface{}, 0, len(lowerArr)+len(higherArr)), lowerArr...), higherArr...)
+	case arrayMergeByIndex:
+		merged := make([]interface{}, len(lowerArr))
+		copy(merged, lowerArr)
+		for i, val := range higherArr {
+			switch {
+			case i >= len(merged):
+				merged = append(merged, val)
+			case val != nil:
+				merged[i] = c.mergeValues(fmt.Sprintf("%s[%d]", key, i), merged[i], val)
+			}
+		}
+		return merged
+	case arrayMergeByID:
+		return c.mergeByID(key, strategy.idField, lowerArr, higherArr)
+	}
+	return higher
+}
+
+// mergeByID merges the map items of the arrays that have the same value of the id field, and appends the others
+func (c Configuration) mergeByID(key string, field string, lower []interface{}, higher []interface{}) []interface{} {
+	merged := make([]interface{}, len(lower))
+	copy(merged, lower)
+	positions := make(map[string]int)
+	for i, val := range merged {
+		if id, ok := itemID(val, field); ok {
+			positions[id] = i
+		}
+	}
+	for _, val := range higher {
+		id, ok := itemID(val, field)
+		if i, found := positions[id]; ok && found {
+			merged[i] = c.mergeValues(fmt.Sprintf("%s[%d]", key, i), merged[i], val)
+			continue
+		}
+		if ok {
+			positions[id] = len(merged)
+		}
+		merged = append(merged, val)
+	}
+	return merged
+}
+
+// itemID returns the value of the id field of an array item, if the item is a map that has the field
+func itemID(item interface{}, field string) (string, bool) {
+	m, ok := toMap(item)
+	if !ok {
+		return "", false
+	}
+	id, found := m[field]
+	if !found {
+		return "", false
+	}
+	return convertToString(id), true
+}
+
+// sameKind returns true if both values are maps or both are arrays
+func sameKind(a interface{}, b interface{}) bool {
+	_, aMap := toMap(a)
+	_, bMap := toMap(b)
+	if aMap || bMap {
+		return aMap && bMap
+	}
+	_, aArr := toArray(a)
+	_, bArr := toArray(b)
+	return aArr && bArr
+}
+
+// strategyFor returns the merge strategy of the arrays of the key, ignoring the array indices in it
+func (c Configuration) strategyFor(key string) MergeStrategy {
+	if strategy, found := c.strategies[strategyKey(key)]; found {
+		return strategy
+	}
+	return MergeReplace
+}
+
+// strategyAncestor finds the outermost key that contains the given key and has a merge strategy set.
+// Returns the ancestor key and the rest of the key inside it, e.g. "servers" and "[1].port" for "servers[1].port".
+func (c Configuration) strategyAncestor(key string) (string, string, bool) {
+	if len(c.strategies) == 0 {
+		return "", "", false
+	}
+	prefix, rest := "", key
+	for rest != "" {
+		bracket := rest[0] == '['
+		head, next := splitKeyHead(rest)
+		if bracket {
+			prefix = fmt.Sprintf("%s[%s]", prefix, head)
+		} else {
+			prefix = joinKey(prefix, head)
+		}
+		if next == "" {
+			break
+		}
+		if _, found := c.strategies[strategyKey(prefix)]; found {
+			return prefix, next, true
+		}
+		rest = next
+	}
+	return "", "", false
+}
+
+// strategyKey removes the array indices from a key, e.g. "servers[1].tags" and "servers.1.tags" become "servers.tags"
+func strategyKey(key string) string {
+	var stripped string
+	for rest := key; rest != ""; {
+		var head string
+		head, rest = splitKeyHead(rest)
+		if _, err := strconv.Atoi(head); err == nil {
+			continue
+		}
+		stripped = joinKey(stripped, head)
+	}
+	return stripped
+}
@@ -0,0 +1,169 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DeepMerge_Maps(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432,\"options\":{\"ssl\":true}}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "base.json",
+	})
+	mockFile("database:\n  password: secret\n  options:\n    timeout: 5\n", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	val, source, found := c.lookup("database")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "yaml:overlay.yaml", source)
+	assert.Equal(t, map[string]interface{}{
+		"host":     "json-host",
+		"port":     5432.0,
+		"password": "secret",
+		"options":  map[string]interface{}{"ssl": true, "timeout": 5},
+	}, val)
+	host, err := c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "json-host", host)
+	// A lower priority value that's not a map is replaced
+	c = c.Set("database", map[string]interface{}{"host": "override-host"})
+	c = c.SetDefault("database", "not a map")
+	val, _ = c.findKey("database")
+	assert.Equal(t, "override-host", val.(map[string]interface{})["host"])
+	assert.Equal(t, "secret", val.(map[string]interface{})["password"])
+	c = c.Set("database", "replaced")
+	val, _ = c.findKey("database")
+	assert.Equal(t, "replaced", val)
+}
+
+func Test_DeepMerge_Unmarshal(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432,\"options\":{\"ssl\":true}}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "base.json",
+	})
+	mockFile("database:\n  password: secret\n  options:\n    timeout: 5\n", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	var cfg struct {
+		Database map[string]interface{} `gonfig:"database"`
+	}
+	assert.Nil(t, c.Unmarshal(&cfg))
+	assert.Equal(t, "json-host", cfg.Database["host"])
+	assert.Equal(t, "secret", cfg.Database["password"])
+}
+
+func Test_MergeStrategies(t *testing.T) {
+	var c Configuration
+	mockFile("{\"tags\":[\"a\",\"b\"],"+
+		"\"servers\":[{\"name\":\"api\",\"port\":80,\"tags\":[\"x\"]},{\"name\":\"web\",\"port\":81}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "base.json",
+	})
+	mockFile("tags:\n  - c\n"+
+		"servers:\n  - name: web\n    port: 8081\n  - name: admin\n    port: 9000\n", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	// Arrays are replaced by default
+	tags, err := c.GetStringArray("tags")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, tags)
+
+	appended := c.SetMergeStrategy("tags", MergeAppend)
+	tags, err = appended.GetStringArray("tags")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+	tag, err := appended.GetString("tags[2]")
+	assert.Nil(t, err)
+	assert.Equal(t, "c", tag)
+
+	byIndex := c.SetMergeStrategy("servers", MergeByIndex)
+	servers, _ := byIndex.findKey("servers")
+	assert.Equal(t, 2, len(servers.([]interface{})))
+	assert.Equal(t, "admin", byIndex.GetStringOrDefault("servers[1].name", ""))
+	assert.Equal(t, "x", byIndex.GetStringOrDefault("servers[0].tags[0]", ""))
+	assert.Equal(t, 8081, byIndex.GetIntOrDefault("servers[0].port", 0))
+
+	byID := c.SetMergeStrategy("servers", MergeByID("name")).SetMergeStrategy("servers.tags", MergeAppend)
+	servers, _ = byID.findKey("servers")
+	assert.Equal(t, 3, len(servers.([]interface{})))
+	assert.Equal(t, "api", byID.GetStringOrDefault("servers[0].name", ""))
+	assert.Equal(t, 80, byID.GetIntOrDefault("servers[0].port", 0))
+	assert.Equal(t, 8081, byID.GetIntOrDefault("servers[1].port", 0))
+	assert.Equal(t, "admin", byID.GetStringOrDefault("servers[2].name", ""))
+	_, err = byID.GetInt("servers[3].port")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// The strategies are copied with the Configuration
+	tags, _ = c.GetStringArray("tags")
+	assert.Equal(t, []string{"c"}, tags)
+}
+
+func Test_MergeStrategies_NestedArrays(t *testing.T) {
+	var c Configuration
+	c = c.AddSource(NewMapSource(map[string]interface{}{
+		"servers": []interface{}{map[string]interface{}{"name": "api", "tags": []interface{}{"x"}}},
+	}))
+	c = c.Set("servers[0].tags", []interface{}{"y"})
+	c = c.SetMergeStrategy("servers.tags", MergeAppend)
+	tags, err := c.GetStringArray("servers[0].tags")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "y"}, tags)
+	assert.Equal(t, "y", c.GetStringOrDefault("servers[0].tags[1]", ""))
+	assert.Equal(t, map[string]interface{}{
+		"servers": []interface{}{map[string]interface{}{"name": "api", "tags": []interface{}{"x", "y"}}},
+	}, c.AllSettings())
+}
+
+func Test_MergeStrategies_AllSettings(t *testing.T) {
+	os.Setenv("DATABASE_HOST", "env-host")
+	defer os.Unsetenv("DATABASE_HOST")
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}, \"tags\":[\"a\",\"b\"]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "base.json",
+	})
+	mockFile("database:\n  password: secret\ntags:\n  - c\n", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	c = c.AddConfigSource(ConfigSource{Type: SourceTypeEnv})
+	c = c.SetMergeStrategy("tags", MergeAppend)
+	settings := c.AllSettings()
+	assert.Equal(t, []interface{}{"a", "b", "c"}, settings["tags"])
+	assert.Equal(t, "env-host", settings["database"].(map[string]interface{})["host"])
+	assert.Equal(t, "secret", settings["database"].(map[string]interface{})["password"])
+}
+
+func Test_strategyKey(t *testing.T) {
+	assert.Equal(t, "servers", strategyKey("servers"))
+	assert.Equal(t, "servers.tags", strategyKey("servers[1].tags"))
+	assert.Equal(t, "servers.tags", strategyKey("servers.1.tags"))
+	assert.Equal(t, "a.b.c", strategyKey("a[0][1].b.c[2]"))
+}
+
+func Test_strategyAncestor(t *testing.T) {
+	c := Configuration{}.SetMergeStrategy("servers", MergeAppend).SetMergeStrategy("servers.tags", MergeAppend)
+	ancestor, rest, ok := c.strategyAncestor("servers[1].tags[0]")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "servers", ancestor)
+	assert.Equal(t, "[1].tags[0]", rest)
+	_, _, ok = c.strategyAncestor("servers")
+	assert.Equal(t, false, ok)
+	_, _, ok = c.strategyAncestor("database.host")
+	assert.Equal(t, false, ok)
+}
@@ -0,0 +1,200 @@
+package gonfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupPath resolves a dotted key such as "database.host" or "servers[1].port" inside the given value.
+// Maps are traversed by key and arrays by index. A key that exists literally at any level (e.g. "database.host" as a flat key)
+// takes precedence over the nested interpretation.
+func lookupPath(value interface{}, key string) (interface{}, bool) {
+	if key == "" {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if val, found := v[key]; found {
+			return val, true
+		}
+		head, rest := splitKeyHead(key)
+		if val, found := v[head]; found {
+			return lookupPath(val, rest)
+		}
+	case map[interface{}]interface{}: // yaml.v2 produces this type for nested mappings
+		if val, found := lookupAnyKey(v, key); found {
+			return val, true
+		}
+		head, rest := splitKeyHead(key)
+		if val, found := lookupAnyKey(v, head); found {
+			return lookupPath(val, rest)
+		}
+	case []interface{}:
+		head, rest := splitKeyHead(key)
+		if i, ok := parseIndex(head, len(v)); ok {
+			return lookupPath(v[i], rest)
+		}
+	case []string:
+		head, rest := splitKeyHead(key)
+		if i, ok := parseIndex(head, len(v)); ok {
+			return lookupPath(v[i], rest)
+		}
+	}
+	return nil, false
+}
+
+// lookupAnyKey finds a key in a map with non-string keys by comparing their string representations
+func lookupAnyKey(m map[interface{}]interface{}, key string) (interface{}, bool) {
+	if val, found := m[key]; found {
+		return val, true
+	}
+	for k, val := range m {
+		if convertToString(k) == key {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// splitKeyHead splits the first segment of a key from the rest of it.
+// "servers[1].port" is split as "servers" and "[1].port", "[1].port" is split as "1" and "port".
+func splitKeyHead(key string) (string, string) {
+	if strings.HasPrefix(key, "[") {
+		if end := strings.Index(key, "]"); end > 0 {
+			return key[1:end], strings.TrimPrefix(key[end+1:], ".")
+		}
+		return key, ""
+	}
+	end := strings.IndexAny(key, ".[")
+	if end < 0 {
+		return key, ""
+	}
+	if key[end] == '.' {
+		return key[:end], key[end+1:]
+	}
+	return key[:end], key[end:]
+}
+
+// parseIndex converts a key segment to an array index if it's a number within the given length
+func parseIndex(segment string, length int) (int, bool) {
+	i, err := strconv.Atoi(segment)
+	if err != nil || i < 0 || i >= length {
+		return 0, false
+	}
+	return i, true
+}
+
+// envKey converts a dotted key to the conventional environment variable name.
+// "database.host" becomes "DATABASE_HOST" and "servers[1].port" becomes "SERVERS_1_PORT".
+func envKey(key string) string {
+	replacer := strings.NewReplacer(".", "_", "[", "_", "]", "", "-", "_")
+	return strings.ToUpper(replacer.Replace(key))
+}
+
+// maxArrayIndex limits the indices of the arrays that are created by setting keys like "servers[0].host",
+// or by assembling indexed variables like SERVERS_0_HOST, so that a key like "a[999999999]" cannot allocate a huge array
+const maxArrayIndex = 1 << 16
+
+// setPath sets the value of a dotted key in the given map, creating the intermediate maps and arrays as needed.
+// If an intermediate key already holds a value that's not a map or an array, the rest of the key is stored literally in the deepest map instead.
+// Returns errIndexTooLarge if an array index in the key is not less than maxArrayIndex.
+func setPath(items map[string]interface{}, key string, value interface{}) error {
+	head, rest := splitKeyHead(key)
+	if rest == "" {
+		items[head] = value
+		return nil
+	}
+	child, ok, err := setChild(items[head], rest, value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		items[key] = value
+		return nil
+	}
+	items[head] = child
+	return nil
+}
+
+// setChild sets the value of a key inside a map, an array, or nil which is replaced by a new map or array.
+// Returns the updated child, or false if the key cannot be set inside it.
+func setChild(child interface{}, key string, value interface{}) (interface{}, bool, error) {
+	head, rest := splitKeyHead(key)
+	switch c := child.(type) {
+	case nil:
+		if !strings.HasPrefix(key, "[") {
+			m := make(map[string]interface{})
+			return m, true, setPath(m, key, value)
+		}
+		return setChild([]interface{}{}, key, value)
+	case map[string]interface{}:
+		return c, true, setPath(c, key, value)
+	case []interface{}:
+		i, err := strconv.Atoi(head)
+		if err != nil || i < 0 {
+			return nil, false, nil
+		}
+		if i >= maxArrayIndex {
+			return nil, false, errIndexTooLarge
+		}
+		for len(c) <= i {
+			c = append(c, nil)
+		}
+		if rest == "" {
+			c[i] = value
+			return c, true, nil
+		}
+		item, ok, err := setChild(c[i], rest, value)
+		if !ok || err != nil {
+			return nil, false, err
+		}
+		c[i] = item
+		return c, true, nil
+	}
+	return nil, false, nil
+}
+
+// flatten returns the leaf values of the nested maps and arrays in value, keyed by their dotted paths
+func flatten(value interface{}, prefix string, output map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			output[prefix] = v
+		}
+		for key, item := range v {
+			flatten(item, joinKey(prefix, key), output)
+		}
+	case map[interface{}]interface{}:
+		if len(v) == 0 && prefix != "" {
+			output[prefix] = v
+		}
+		for key, item := range v {
+			flatten(item, joinKey(prefix, convertToString(key)), output)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			output[prefix] = v
+		}
+		for i, item := range v {
+			flatten(item, fmt.Sprintf("%s[%d]", prefix, i), output)
+		}
+	case []string: // Arrays of the env and dotenv sources
+		if len(v) == 0 {
+			output[prefix] = []interface{}{}
+		}
+		for i, item := range v {
+			output[fmt.Sprintf("%s[%d]", prefix, i)] = item
+		}
+	default:
+		output[prefix] = value
+	}
+}
+
+// joinKey appends a map key to a dotted key
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
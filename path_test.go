@@ -0,0 +1,141 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lookupPath_JSON(t *testing.T) {
+	mockFile("{\"database\":{\"host\":\"localhost\",\"port\":5432}, \"servers\":[{\"port\":80},{\"port\":443}], \"flat.key\":\"flat\"}", nil)
+	items, err := readJSON("nothing")
+	assert.Nil(t, err)
+	// Nested map
+	val, found := lookupPath(items, "database.host")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "localhost", val)
+	// Array index inside the path
+	val, found = lookupPath(items, "servers[1].port")
+	assert.Equal(t, true, found)
+	assert.Equal(t, 443.0, val)
+	// Dotted index notation is also accepted
+	val, found = lookupPath(items, "servers.0.port")
+	assert.Equal(t, true, found)
+	assert.Equal(t, 80.0, val)
+	// Literal keys containing dots take precedence
+	val, found = lookupPath(items, "flat.key")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "flat", val)
+	// Missing keys and out of range indices
+	_, found = lookupPath(items, "database.user")
+	assert.Equal(t, false, found)
+	_, found = lookupPath(items, "servers[2].port")
+	assert.Equal(t, false, found)
+	_, found = lookupPath(items, "database.host.name")
+	assert.Equal(t, false, found)
+}
+
+func Test_lookupPath_Yaml(t *testing.T) {
+	mockFile("database:\n  host: localhost\n  port: 5432\nservers:\n  - port: 80\n  - port: 443\ncodes:\n  404: notfound", nil)
+	items, err := readYaml("nothing")
+	assert.Nil(t, err)
+	val, found := lookupPath(items, "database.port")
+	assert.Equal(t, true, found)
+	assert.Equal(t, 5432, val)
+	val, found = lookupPath(items, "servers[0].port")
+	assert.Equal(t, true, found)
+	assert.Equal(t, 80, val)
+	// yaml.v2 keeps non-string keys as they are
+	val, found = lookupPath(items, "codes.404")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "notfound", val)
+}
+
+func Test_splitKeyHead(t *testing.T) {
+	head, rest := splitKeyHead("servers[1].port")
+	assert.Equal(t, "servers", head)
+	assert.Equal(t, "[1].port", rest)
+	head, rest = splitKeyHead(rest)
+	assert.Equal(t, "1", head)
+	assert.Equal(t, "port", rest)
+	head, rest = splitKeyHead(rest)
+	assert.Equal(t, "port", head)
+	assert.Equal(t, "", rest)
+}
+
+func Test_envKey(t *testing.T) {
+	assert.Equal(t, "DATABASE_HOST", envKey("database.host"))
+	assert.Equal(t, "SERVERS_1_PORT", envKey("servers[1].port"))
+	assert.Equal(t, "LOG_LEVEL", envKey("log-level"))
+}
+
+func Test_findKey_Nested(t *testing.T) {
+	var c Configuration
+	mockFile("{\"database\":{\"host\":\"localhost\",\"port\":5432}, \"servers\":[{\"port\":80},{\"port\":443}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	val, err := c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", val)
+	intVal, err := c.GetInt("servers[1].port")
+	assert.Nil(t, err)
+	assert.Equal(t, 443, intVal)
+	// Env variables override nested keys by their conventional names
+	os.Setenv("DATABASE_HOST", "db.internal")
+	defer os.Unsetenv("DATABASE_HOST")
+	os.Setenv("SERVERS_1_PORT", "8443")
+	defer os.Unsetenv("SERVERS_1_PORT")
+	val, err = c.GetString("database.host")
+	assert.Nil(t, err)
+	assert.Equal(t, "db.internal", val)
+	intVal, err = c.GetInt("servers[1].port")
+	assert.Nil(t, err)
+	assert.Equal(t, 8443, intVal)
+}
+
+func Test_setPath(t *testing.T) {
+	items := make(map[string]interface{})
+	setPath(items, "database.host", "localhost")
+	setPath(items, "database.port", 5432)
+	setPath(items, "name", "svc")
+	// name is not a map, so name.first is kept as a flat key
+	setPath(items, "name.first", "first")
+	assert.Equal(t, map[string]interface{}{
+		"database":   map[string]interface{}{"host": "localhost", "port": 5432},
+		"name":       "svc",
+		"name.first": "first",
+	}, items)
+	val, found := lookupPath(items, "name.first")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "first", val)
+}
+
+func Test_setPath_Arrays(t *testing.T) {
+	items := make(map[string]interface{})
+	setPath(items, "servers[1].port", 443)
+	setPath(items, "servers[0].port", 80)
+	setPath(items, "servers.1.host", "b")
+	setPath(items, "tags[0]", "x")
+	assert.Equal(t, map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"port": 80},
+			map[string]interface{}{"port": 443, "host": "b"},
+		},
+		"tags": []interface{}{"x"},
+	}, items)
+	// An index segment on a scalar is stored literally
+	setPath(items, "tags[0][1]", "y")
+	val, found := lookupPath(items, "tags[0][1]")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "y", val)
+	// Huge indices are not allocated
+	assert.Equal(t, errIndexTooLarge, setPath(items, "servers.999999999", "x"))
+	assert.Equal(t, errIndexTooLarge, setPath(items, "servers[1].ports[65536]", 1))
+	assert.Nil(t, setPath(items, "servers[1].ports[65535]", 1))
+}
@@ -0,0 +1,153 @@
+package gonfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// decodeProperties parses the contents of a Java .properties file.
+// Keys and values are separated by "=", ":" or whitespace, lines starting with "#" or "!" are comments,
+// and lines ending with an odd number of backslashes continue on the next line.
+// Escapes including \uXXXX are processed in both keys and values. Dotted keys are nested like sections of an INI file,
+// so a key like "log" cannot have both a value and nested keys like "log.level".
+func decodeProperties(data []byte) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for endsWithContinuation(line) {
+			line = line[:len(line)-1]
+			if i+1 >= len(lines) {
+				break
+			}
+			i++
+			line += strings.TrimLeft(lines[i], " \t\f")
+		}
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, &ParseError{Format: "properties", Line: lineNo, Msg: err.Error()}
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, &ParseError{Format: "properties", Line: lineNo, Msg: err.Error()}
+		}
+		if other, conflict := nestedConflict(output, key); conflict {
+			return nil, &ParseError{Format: "properties", Line: lineNo, Msg: fmt.Sprintf("key %q conflicts with the key %q", key, other)}
+		}
+		if err := setPath(output, key, value); err != nil {
+			return nil, &ParseError{Format: "properties", Line: lineNo, Msg: fmt.Sprintf("array index in the key %q is not less than %d", key, maxArrayIndex)}
+		}
+	}
+	return output, nil
+}
+
+// nestedConflict returns a key that conflicts with the given key, which is either nested in it or a parent of it that has a value,
+// e.g. "log.level" for "log", so that the conflicting keys are not settled by their order
+func nestedConflict(items map[string]interface{}, key string) (string, bool) {
+	if val, found := lookupPath(items, key); found && composite(val) {
+		leaves := make(map[string]interface{})
+		flatten(val, key, leaves)
+		nested := make([]string, 0, len(leaves))
+		for leaf := range leaves {
+			nested = append(nested, leaf)
+		}
+		sort.Strings(nested)
+		return nested[0], true
+	}
+	for _, ancestor := range keyAncestors(key) {
+		if val, found := lookupPath(items, ancestor[0]); found && !composite(val) {
+			return ancestor[0], true
+		}
+	}
+	return "", false
+}
+
+// endsWithContinuation reports whether the line ends with an odd number of backslashes
+func endsWithContinuation(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped separator
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty processes the escapes of a key or a value
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			r, err := unicodeEscape(s, i+1)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside of the BMP are written as surrogate pairs
+			if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, err := unicodeEscape(s, i+3); err == nil {
+					r = utf16.DecodeRune(r, low)
+					i += 6
+				}
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// unicodeEscape parses the 4 hex digits of a \uXXXX escape starting at the given index
+func unicodeEscape(s string, start int) (rune, error) {
+	if start+4 > len(s) {
+		return 0, fmt.Errorf("malformed \\u escape %q", s[start-2:])
+	}
+	code, err := strconv.ParseUint(s[start:start+4], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\u escape %q", s[start-2:start+4])
+	}
+	return rune(code), nil
+}
@@ -0,0 +1,50 @@
+package gonfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeProperties_Success(t *testing.T) {
+	payload := "# comment\n! another comment\ndb.host = localhost\ndb.port:5432\nname svc\nmessage = first \\\n    second\nunicode = caf\\u00e9 \\uD83D\\uDE00\nescaped\\ key = a\\=b\\\\\nempty\n"
+	result, err := decodeProperties([]byte(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"name":        "svc",
+		"message":     "first second",
+		"unicode":     "café 😀",
+		"escaped key": "a=b\\",
+		"empty":       "",
+	}, result)
+}
+
+func Test_decodeProperties_Errors(t *testing.T) {
+	_, err := decodeProperties([]byte("key1 = value1\nkey2 = \\u00"))
+	assert.EqualError(t, err, "properties: line 2: malformed \\u escape \"\\\\u00\"")
+	_, err = decodeProperties([]byte("key1 = \\uXYZW"))
+	assert.EqualError(t, err, "properties: line 1: malformed \\u escape \"\\\\uXYZW\"")
+	_, err = decodeProperties([]byte("log.level=DEBUG\nlog=INFO"))
+	assert.EqualError(t, err, "properties: line 2: key \"log\" conflicts with the key \"log.level\"")
+	_, err = decodeProperties([]byte("log=INFO\nlog.level=DEBUG"))
+	assert.EqualError(t, err, "properties: line 2: key \"log.level\" conflicts with the key \"log\"")
+	_, err = decodeProperties([]byte("a[0]=x\na[999999999]=y"))
+	assert.EqualError(t, err, "properties: line 2: array index in the key \"a[999999999]\" is not less than 65536")
+}
+
+func Test_AddConfigSource_Properties(t *testing.T) {
+	var c Configuration
+	mockFile("db.host=localhost\ndb.port=5432", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeProperties,
+		FilePath: "testing.properties",
+	})
+	assert.Equal(t, false, c.HasError)
+	port, err := c.GetInt("db.port")
+	assert.Nil(t, err)
+	assert.Equal(t, 5432, port)
+}
@@ -0,0 +1,77 @@
+package gonfig
+
+import (
+	"sort"
+)
+
+// SourceValue is the value of a key in a config source
+type SourceValue struct {
+	// Source is the name of the config source, e.g. "json:config.json", "env", "default" or "override"
+	Source string
+	// Value is the raw value in the config source
+	Value interface{}
+}
+
+// Explanation describes where the value of a key comes from
+type Explanation struct {
+	// Key is the explained key
+	Key string
+	// Found is false if none of the config sources have the key
+	Found bool
+	// Winner is the value that's returned for the key, merged with the lower priority values like the getters do, and the source that supplied it
+	Winner SourceValue
+	// Shadowed are the raw values of the key in the other sources, which are overridden by Winner or merged into it, from the highest priority to the lowest
+	Shadowed []SourceValue
+}
+
+// Explain returns the source that supplies the value of the key, and every value of the key that's shadowed in lower priority sources
+func (c Configuration) Explain(key string) Explanation {
+	explanation := Explanation{Key: key}
+	val, source, found := c.lookup(key)
+	if !found {
+		return explanation
+	}
+	explanation.Found = true
+	explanation.Winner = SourceValue{Source: source, Value: val}
+	winnerSkipped := false
+	layers := c.layers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].Name() == source && !winnerSkipped {
+			winnerSkipped = true
+			continue
+		}
+		if val, found := layers[i].Lookup(key); found {
+			explanation.Shadowed = append(explanation.Shadowed, SourceValue{Source: layers[i].Name(), Value: val})
+		}
+	}
+	return explanation
+}
+
+// Provenance returns the name of the source that supplies the value of every key, keyed by the dotted paths of the leaf values.
+// Keys are enumerated from the sources that implement Enumerable, so the keys that exist only in the env source are not listed,
+// but the env source is reported as the source of the keys it overrides.
+func (c Configuration) Provenance() map[string]string {
+	provenance := make(map[string]string)
+	for _, key := range c.allKeys() {
+		if _, source, found := c.lookup(key); found {
+			provenance[key] = source
+		}
+	}
+	return provenance
+}
+
+// allKeys returns the sorted dotted paths of the leaf values in all the sources that implement Enumerable
+func (c Configuration) allKeys() []string {
+	leaves := make(map[string]interface{})
+	for _, layer := range c.layers() {
+		if enumerable, ok := layer.(Enumerable); ok {
+			flatten(enumerable.Settings(), "", leaves)
+		}
+	}
+	keys := make([]string, 0, len(leaves))
+	for key := range leaves {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package gonfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Explain(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("database.host", "default-host")
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	mockFile("database:\n  host: yaml-host", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "overlay.yaml",
+	})
+	os.Setenv("DATABASE_HOST", "env-host")
+	defer os.Unsetenv("DATABASE_HOST")
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	explanation := c.Explain("database.host")
+	assert.Equal(t, Explanation{
+		Key:    "database.host",
+		Found:  true,
+		Winner: SourceValue{Source: "env", Value: "env-host"},
+		Shadowed: []SourceValue{
+			{Source: "yaml:overlay.yaml", Value: "yaml-host"},
+			{Source: "json:config.json", Value: "json-host"},
+			{Source: "default", Value: "default-host"},
+		},
+	}, explanation)
+	explanation = c.Explain("database.port")
+	assert.Equal(t, true, explanation.Found)
+	assert.Equal(t, SourceValue{Source: "json:config.json", Value: 5432.0}, explanation.Winner)
+	assert.Nil(t, explanation.Shadowed)
+	explanation = c.Explain("database.user")
+	assert.Equal(t, Explanation{Key: "database.user"}, explanation)
+	// The winner is the merged value the getters return
+	explanation = c.Explain("database")
+	assert.Equal(t, SourceValue{Source: "env", Value: map[string]interface{}{"host": "env-host", "port": 5432.0}}, explanation.Winner)
+	assert.Equal(t, 3, len(explanation.Shadowed))
+	assert.Equal(t, SourceValue{Source: "yaml:overlay.yaml", Value: map[interface{}]interface{}{"host": "yaml-host"}}, explanation.Shadowed[0])
+	assert.Equal(t, "json:config.json", explanation.Shadowed[1].Source)
+	assert.Equal(t, "default", explanation.Shadowed[2].Source)
+	// including the arrays assembled from the unprefixed env variables
+	mockFile("{\"servers\":[{\"host\":\"json-host\"}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "servers.json",
+	})
+	os.Setenv("SERVERS_0_HOST", "env-host")
+	defer os.Unsetenv("SERVERS_0_HOST")
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	explanation = c.Explain("servers")
+	assert.Equal(t, SourceValue{Source: "env", Value: []interface{}{map[string]interface{}{"host": "env-host"}}}, explanation.Winner)
+	assert.Equal(t, []SourceValue{{Source: "json:servers.json", Value: []interface{}{map[string]interface{}{"host": "json-host"}}}}, explanation.Shadowed)
+}
+
+func Test_Provenance(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("log.level", "info")
+	mockFile("{\"database\":{\"host\":\"json-host\",\"port\":5432}, \"servers\":[{\"port\":80}]}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "config.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	c = c.Set("servers[0].port", 8080)
+	os.Setenv("DATABASE_PORT", "6543")
+	defer os.Unsetenv("DATABASE_PORT")
+	assert.Equal(t, map[string]string{
+		"database.host":   "json:config.json",
+		"database.port":   "env",
+		"log.level":       "default",
+		"servers[0].port": "override",
+	}, c.Provenance())
+}
@@ -0,0 +1,117 @@
+package gonfig
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Source is the interface implemented by every config source, built-in or third-party.
+type Source interface {
+	// Name returns a human readable name of the source, e.g. "json:config.json"
+	Name() string
+	// Load reads the source. It's called once when the source is added to a Configuration
+	Load() error
+	// Lookup returns the value of the given dotted key if the source contains it
+	Lookup(key string) (interface{}, bool)
+}
+
+// Enumerable is implemented by the sources that can list all of their items, e.g. to report the keys that change on reload.
+// The built-in sources except the env source implement it.
+type Enumerable interface {
+	// Settings returns the items of the source as nested maps. The returned map must not be modified
+	Settings() map[string]interface{}
+}
+
+// SourceFactory creates a Source from the description of a ConfigSource
+type SourceFactory func(ConfigSource) Source
+
+// Decoder parses the contents of a file into configuration items
+type Decoder func(data []byte) (map[string]interface{}, error)
+
+var (
+	registryLock    sync.RWMutex
+	sourceFactories = map[SourceType]SourceFactory{}
+	decoders        = map[SourceType]Decoder{}
+)
+
+func init() {
+	RegisterSourceType(SourceTypeEnv, newEnvSource)
+	RegisterSourceType(SourceTypeDotenv, newDotenvSource)
+	RegisterSourceType(SourceTypeFlags, newFlagSourceFromConfig)
+	RegisterDecoder(SourceTypeJSON, decodeJSON)
+	RegisterDecoder(SourceTypeYaml, decodeYaml)
+	RegisterDecoder(SourceTypeTOML, decodeTOML)
+	RegisterDecoder(SourceTypeINI, decodeINI)
+	RegisterDecoder(SourceTypeProperties, decodeProperties)
+	RegisterDecoder(SourceTypeHCL, decodeHCL)
+}
+
+// RegisterSourceType makes a source type available to AddConfigSource.
+// Registering a type that's already registered replaces the previous factory.
+func RegisterSourceType(t SourceType, factory SourceFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	sourceFactories[t] = factory
+}
+
+// RegisterDecoder makes a file format available to AddConfigSource as a source type.
+// The file at ConfigSource.FilePath is read and parsed by the decoder, and its items are looked up by dotted keys.
+func RegisterDecoder(t SourceType, decoder Decoder) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	decoders[t] = decoder
+}
+
+// newSource creates the Source that's registered for the type of the ConfigSource
+func newSource(s ConfigSource) (Source, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	if factory, found := sourceFactories[s.Type]; found {
+		return factory(s), nil
+	}
+	if decoder, found := decoders[s.Type]; found {
+		return &fileSource{config: s, decode: decoder}, nil
+	}
+	return nil, fmt.Errorf("Unknown source type %q", s.Type)
+}
+
+// fileSource is a Source that reads its items from a file, or from the content given in its ConfigSource, using a Decoder
+type fileSource struct {
+	config ConfigSource
+	decode Decoder
+	items  map[string]interface{}
+}
+
+func (s *fileSource) Name() string {
+	name := s.config.FilePath
+	if name == "" {
+		switch {
+		case s.config.Data != nil:
+			name = "bytes"
+		case s.config.Reader != nil:
+			name = "reader"
+		}
+	}
+	return fmt.Sprintf("%s:%s", s.config.Type, name)
+}
+
+func (s *fileSource) Load() error {
+	data, err := readSource(s.config)
+	if err != nil {
+		return err
+	}
+	items, err := s.decode(data)
+	if err != nil {
+		return toParseError(s.config.Type, data, err)
+	}
+	s.items = items
+	return nil
+}
+
+func (s *fileSource) Lookup(key string) (interface{}, bool) {
+	return lookupPath(s.items, key)
+}
+
+func (s *fileSource) Settings() map[string]interface{} {
+	return s.items
+}
@@ -0,0 +1,102 @@
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSource struct {
+	items   map[string]interface{}
+	loadErr error
+}
+
+func (s *testSource) Name() string {
+	return "test"
+}
+
+func (s *testSource) Load() error {
+	return s.loadErr
+}
+
+func (s *testSource) Lookup(key string) (interface{}, bool) {
+	return lookupPath(s.items, key)
+}
+
+func Test_RegisterSourceType(t *testing.T) {
+	RegisterSourceType("test", func(s ConfigSource) Source {
+		return &testSource{items: map[string]interface{}{"key1": s.FilePath}}
+	})
+	t.Cleanup(func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		delete(sourceFactories, "test")
+	})
+	var c Configuration
+	mockFile("{\"key1\":\"value1\", \"key2\":\"value2\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type:     "test",
+		FilePath: "from test source",
+	})
+	assert.Equal(t, false, c.HasError)
+	val, found := c.findKey("key1")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "from test source", val)
+	val, found = c.findKey("key2")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "value2", val)
+}
+
+func Test_RegisterDecoder(t *testing.T) {
+	RegisterDecoder("keyvalue", func(data []byte) (map[string]interface{}, error) {
+		items := make(map[string]interface{})
+		for _, line := range strings.Split(string(data), "\n") {
+			if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
+				items[parts[0]] = parts[1]
+			}
+		}
+		return items, nil
+	})
+	var c Configuration
+	mockFile("key1=value1\nkey2=value2", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     "keyvalue",
+		FilePath: "testing.kv",
+	})
+	assert.Equal(t, false, c.HasError)
+	assert.Equal(t, "keyvalue:testing.kv", c.sources[0].provider.Name())
+	val, found := c.findKey("key2")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "value2", val)
+}
+
+func Test_AddConfigSource_UnknownType(t *testing.T) {
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type: "unknown",
+	})
+	assert.Equal(t, true, c.HasError)
+	assert.EqualError(t, c.sources[0].err, "Unknown source type \"unknown\"")
+	_, found := c.findKey("key1")
+	assert.Equal(t, false, found)
+}
+
+func Test_AddSource(t *testing.T) {
+	var c Configuration
+	c = c.AddSource(&testSource{items: map[string]interface{}{"key1": "value1"}})
+	val, found := c.findKey("key1")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "value1", val)
+	// A source that fails to load is kept with its error but never looked up
+	c = c.AddSource(&testSource{items: map[string]interface{}{"key1": "value2"}, loadErr: errors.New("Load error")})
+	assert.Equal(t, true, c.HasError)
+	val, found = c.findKey("key1")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "value1", val)
+}
@@ -0,0 +1,69 @@
+package gonfig
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store is a goroutine-safe container of a Configuration.
+// Reads are lock-free: Load returns an immutable snapshot that can be used without synchronization.
+// Writes are serialized and swap in a new snapshot atomically, so they never affect the snapshots that are already loaded.
+type Store struct {
+	current atomic.Value // Configuration
+	writer  sync.Mutex
+}
+
+// NewStore creates a Store that holds the given Configuration
+func NewStore(c Configuration) *Store {
+	s := &Store{}
+	s.current.Store(c)
+	return s
+}
+
+// Load returns the current snapshot of the Configuration
+func (s *Store) Load() Configuration {
+	return s.current.Load().(Configuration)
+}
+
+// Update replaces the Configuration with the one returned by the update function.
+// The function is called with the current Configuration, and no other update happens until it returns.
+func (s *Store) Update(update func(Configuration) Configuration) {
+	s.writer.Lock()
+	defer s.writer.Unlock()
+	s.current.Store(update(s.Load()))
+}
+
+// AddConfigSource adds a config source to the Configuration in the Store. See Configuration.AddConfigSource.
+func (s *Store) AddConfigSource(cs ConfigSource) {
+	s.Update(func(c Configuration) Configuration {
+		return c.AddConfigSource(cs)
+	})
+}
+
+// AddSource adds a custom Source implementation to the Configuration in the Store. See Configuration.AddSource.
+func (s *Store) AddSource(src Source) {
+	s.Update(func(c Configuration) Configuration {
+		return c.AddSource(src)
+	})
+}
+
+// SetDefault sets the default value of a key in the Store. See Configuration.SetDefault.
+func (s *Store) SetDefault(key string, value interface{}) {
+	s.Update(func(c Configuration) Configuration {
+		return c.SetDefault(key, value)
+	})
+}
+
+// Set overrides the value of a key in the Store. See Configuration.Set.
+func (s *Store) Set(key string, value interface{}) {
+	s.Update(func(c Configuration) Configuration {
+		return c.Set(key, value)
+	})
+}
+
+// SetMergeStrategy sets how the arrays of the key are merged in the Store. See Configuration.SetMergeStrategy.
+func (s *Store) SetMergeStrategy(key string, strategy MergeStrategy) {
+	s.Update(func(c Configuration) Configuration {
+		return c.SetMergeStrategy(key, strategy)
+	})
+}
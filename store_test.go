@@ -0,0 +1,82 @@
+package gonfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Store(t *testing.T) {
+	var c Configuration
+	c = c.SetDefault("http.port", 8080)
+	s := NewStore(c)
+	snapshot := s.Load()
+	s.AddSource(NewMapSource(map[string]interface{}{"http": map[string]interface{}{"port": 9090}}))
+	assert.Equal(t, 9090, s.Load().GetIntOrDefault("http.port", 0))
+	s.Set("http.port", 7070)
+	assert.Equal(t, 7070, s.Load().GetIntOrDefault("http.port", 0))
+	s.SetDefault("http.host", "localhost")
+	assert.Equal(t, "localhost", s.Load().GetStringOrDefault("http.host", ""))
+	s.AddConfigSource(ConfigSource{
+		Type: SourceTypeJSON,
+		Data: []byte("{\"http\":{\"host\":\"0.0.0.0\"}}"),
+	})
+	assert.Equal(t, "0.0.0.0", s.Load().GetStringOrDefault("http.host", ""))
+	// Snapshots are immutable
+	assert.Equal(t, 8080, snapshot.GetIntOrDefault("http.port", 0))
+	assert.Equal(t, "", snapshot.GetStringOrDefault("http.host", ""))
+}
+
+func Test_Store_Concurrency(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, "{\"counter\":0}")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: path,
+	})
+	s := NewStore(c)
+	w := s.Watch(WatchOptions{Interval: time.Millisecond, Debounce: time.Millisecond})
+	defer w.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(i int) { // Writers
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.Set(fmt.Sprintf("writer%d", i), j)
+				s.SetDefault("default", j)
+			}
+		}(i)
+		go func() { // Readers
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, _ = s.Load().GetInt("counter")
+				_, _ = w.Config().GetInt("writer0")
+			}
+		}()
+		go func(i int) { // Reloaders
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_ = w.Reload()
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, 49, s.Load().GetIntOrDefault(fmt.Sprintf("writer%d", i), 0))
+	}
+	// Reloads don't lose the values that are set concurrently
+	writeTestFile(t, path, "{\"counter\":1}")
+	assert.Nil(t, w.Reload())
+	assert.Equal(t, 1, s.Load().GetIntOrDefault("counter", 0))
+	assert.Equal(t, 49, s.Load().GetIntOrDefault("writer0", 0))
+	assert.Equal(t, s, w.Store())
+}
@@ -0,0 +1,397 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// tagName is the struct tag used to map struct fields to configuration keys
+const tagName = "gonfig"
+
+// FieldError describes a struct field that could not be filled from the config sources
+type FieldError struct {
+	// Field is the path of the field in the struct, e.g. Database.Port
+	Field string
+	// Key is the configuration key the field is mapped to, e.g. db.port
+	Key string
+	// Err is the reason of the failure
+	Err error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Field, e.Key, e.Err)
+}
+
+// UnmarshalError is returned by Unmarshal and Bind when one or more fields could not be filled.
+// It lists every failing field instead of stopping at the first one.
+type UnmarshalError struct {
+	Fields []FieldError
+}
+
+func (e *UnmarshalError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return fmt.Sprintf("%d field(s) could not be unmarshalled: %s", len(e.Fields), strings.Join(messages, "; "))
+}
+
+// Unmarshal fills the struct pointed by target from the config sources.
+// Fields are mapped to keys by their `gonfig` tag (e.g. `gonfig:"db.host"`), or by their lowercased name if they are not tagged.
+// Keys of nested structs are relative to the key of their parent field, and fields tagged with "-" are skipped.
+// Fields whose keys are not found among config sources are left untouched, so they can be preset with default values.
+// The items of slices are converted like the array getters do, by StrictArrays, TrimArrayElements and SkipEmptyArrayElements.
+// Returns an *UnmarshalError listing every field that could not be converted.
+func (c Configuration) Unmarshal(target interface{}) error {
+	return c.Bind("", target)
+}
+
+// Bind fills the struct pointed by target from the keys under the given prefix, e.g. Bind("database", &dbConfig).
+// See Unmarshal for the field mapping rules.
+func (c Configuration) Bind(prefix string, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("The target must be a non-nil pointer to a struct")
+	}
+	d := decoder{config: c}
+	d.decodeStruct(prefix, rv.Elem().Type().Name(), rv.Elem())
+	if len(d.errors) > 0 {
+		return &UnmarshalError{Fields: d.errors}
+	}
+	return nil
+}
+
+type decoder struct {
+	config Configuration
+	errors []FieldError
+	// visiting are the struct types on the current descent path, so that self-referential types like `type Node struct{ Next *Node }` terminate
+	visiting map[reflect.Type]bool
+}
+
+// decodeKey fills rv from the given key and returns whether anything was set.
+// Structs and slices of structs are resolved field by field so that every field is looked up through all config sources.
+func (d *decoder) decodeKey(key string, field string, rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Struct:
+		if isComposite(rv.Type()) {
+			return d.decodeStruct(key, field, rv)
+		}
+	case reflect.Ptr:
+		if isComposite(rv.Type()) {
+			elem := reflect.New(rv.Type().Elem())
+			if !d.decodeKey(key, field, elem.Elem()) {
+				return false
+			}
+			rv.Set(elem)
+			return true
+		}
+	case reflect.Slice:
+		if isComposite(rv.Type().Elem()) {
+			return d.decodeCompositeSlice(key, field, rv)
+		}
+	}
+	val, source, found := d.config.lookup(key)
+	if !found {
+		return false
+	}
+	if err := d.decodeValue(key, source, val, rv); err != nil {
+		d.errors = append(d.errors, FieldError{Field: field, Key: key, Err: withKey(err, key, source)})
+		return false
+	}
+	return true
+}
+
+// decodeStruct fills the fields of a struct from the keys under the given key.
+// A struct type that's already being decoded higher on the path is only descended into again if the key exists, which stops the recursion of self-referential types.
+func (d *decoder) decodeStruct(key string, field string, rv reflect.Value) bool {
+	set := false
+	rt := rv.Type()
+	if d.visiting[rt] {
+		if _, found := d.config.findKey(key); !found {
+			return false
+		}
+	} else {
+		if d.visiting == nil {
+			d.visiting = make(map[reflect.Type]bool)
+		}
+		d.visiting[rt] = true
+		defer delete(d.visiting, rt)
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		childKey := name
+		if key != "" {
+			childKey = key + "." + name
+		}
+		childField := sf.Name
+		if field != "" { // The root field is empty for anonymous structs
+			childField = field + "." + sf.Name
+		}
+		if d.decodeKey(childKey, childField, rv.Field(i)) {
+			set = true
+		}
+	}
+	return set
+}
+
+func (d *decoder) decodeCompositeSlice(key string, field string, rv reflect.Value) bool {
+	val, found := d.config.findKey(key)
+	if !found {
+		return false
+	}
+	items, ok := toArray(val)
+	if !ok {
+		d.errors = append(d.errors, FieldError{Field: field, Key: key, Err: ErrNotArray})
+		return false
+	}
+	slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+	for i := range items {
+		d.decodeKey(fmt.Sprintf("%s[%d]", key, i), fmt.Sprintf("%s[%d]", field, i), slice.Index(i))
+	}
+	rv.Set(slice)
+	return true
+}
+
+// fieldKey returns the configuration key of a struct field, or false if the field should be skipped
+func fieldKey(sf reflect.StructField) (string, bool) {
+	if sf.PkgPath != "" { // unexported field
+		return "", false
+	}
+	name := sf.Tag.Get(tagName)
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, true
+}
+
+// isComposite reports whether the values of the type are made of other configuration keys
+func isComposite(rt reflect.Type) bool {
+	for !hasConverter(rt) {
+		if rt.Kind() != reflect.Ptr {
+			return rt.Kind() == reflect.Struct
+		}
+		rt = rt.Elem()
+	}
+	return false
+}
+
+// toArray returns the items of the array types the config sources produce
+func toArray(val interface{}) ([]interface{}, bool) {
+	switch val := val.(type) {
+	case []interface{}:
+		return val, true
+	case []string:
+		arr := make([]interface{}, len(val))
+		for i, value := range val {
+			arr[i] = value
+		}
+		return arr, true
+	}
+	return nil, false
+}
+
+// sourceArray returns the items of an array value of the given source like toArray.
+// A flag that's given once is a single value instead of a []string, so a scalar from a flag source is an array of one item.
+func sourceArray(val interface{}, source string) ([]interface{}, bool) {
+	if items, ok := toArray(val); ok {
+		return items, true
+	}
+	isFlag := source == string(SourceTypeFlags) || strings.HasPrefix(source, string(SourceTypeFlags)+":")
+	if !isFlag || val == nil {
+		return nil, false
+	}
+	if _, ok := toMap(val); ok {
+		return nil, false
+	}
+	return []interface{}{val}, true
+}
+
+// decodeValue converts a raw value of the key read from a config source into rv using the converters.
+// The registered converters are tried first, then time.Duration and time.Time, then encoding.TextUnmarshaler, then the kind of rv.
+// The items of slices are converted like the array getters do, by the array options of the Configuration.
+func (d *decoder) decodeValue(key string, source string, val interface{}, rv reflect.Value) error {
+	if convert, found := lookupConverter(rv.Type()); found {
+		return convertValue(val, rv, convert)
+	}
+	switch rv.Type() {
+	case durationType:
+		unit := d.config.DurationUnit
+		if unit == 0 {
+			unit = time.Second
+		}
+		duration, err := convertToDuration(val, unit)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(duration))
+		return nil
+	case timeType:
+		t, err := convertToTime(val)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if isTextUnmarshaler(rv.Type()) && !isCompositeValue(val) {
+		return unmarshalText(val, rv)
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		if val == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if !reflect.TypeOf(val).AssignableTo(rv.Type()) {
+			return fmt.Errorf("The value cannot be assigned to %s", rv.Type())
+		}
+		rv.Set(reflect.ValueOf(val))
+	case reflect.String:
+		rv.SetString(convertToString(val))
+	case reflect.Bool:
+		b, err := d.config.convertToBool(val)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := convertToIntSize(val, 64)
+		if err == nil && rv.OverflowInt(i) {
+			err = errOverflow
+		}
+		if err != nil {
+			return withType(err, val, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := convertToUintSize(val, 64)
+		if err == nil && rv.OverflowUint(u) {
+			err = errOverflow
+		}
+		if err != nil {
+			return withType(err, val, rv.Type())
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := convertToFloat(val)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowFloat(f) {
+			return &ConversionError{Value: val, Type: rv.Type().String(), Err: errOverflow}
+		}
+		rv.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(rv.Type().Elem())
+		if err := d.decodeValue(key, source, val, elem.Elem()); err != nil {
+			return err
+		}
+		rv.Set(elem)
+	case reflect.Slice:
+		items, ok := sourceArray(val, source)
+		if !ok {
+			return ErrNotArray
+		}
+		slice := reflect.MakeSlice(rv.Type(), 0, len(items))
+		for i, item := range items {
+			item, ok := d.config.arrayElement(item)
+			if !ok {
+				continue
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.decodeValue(fmt.Sprintf("%s[%d]", key, i), source, item, elem); err != nil {
+				if d.config.StrictArrays {
+					return withKey(err, fmt.Sprintf("%s[%d]", key, i), source)
+				}
+				continue
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		rv.Set(slice)
+	case reflect.Map:
+		return d.decodeMap(key, source, val, rv)
+	case reflect.Struct:
+		return d.decodeStructValue(key, source, val, rv)
+	default:
+		return fmt.Errorf("Unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+func (d *decoder) decodeMap(key string, source string, val interface{}, rv reflect.Value) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("Unsupported map key type %s", rv.Type().Key())
+	}
+	items, ok := toMap(val)
+	if !ok {
+		return errors.New("The value is not a map")
+	}
+	m := reflect.MakeMapWithSize(rv.Type(), len(items))
+	for k, item := range items {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := d.decodeValue(joinKey(key, k), source, item, elem); err != nil {
+			return nestedError(err, joinKey(key, k), source, k)
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+	}
+	rv.Set(m)
+	return nil
+}
+
+// decodeStructValue fills a struct from a raw map value, used for structs nested in maps
+func (d *decoder) decodeStructValue(key string, source string, val interface{}, rv reflect.Value) error {
+	items, ok := toMap(val)
+	if !ok {
+		return errors.New("The value is not a map")
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		item, found := lookupPath(items, name)
+		if !found {
+			continue
+		}
+		if err := d.decodeValue(joinKey(key, name), source, item, rv.Field(i)); err != nil {
+			return nestedError(err, joinKey(key, name), source, sf.Name)
+		}
+	}
+	return nil
+}
+
+// nestedError sets the key of a *ConversionError of a value nested in a map or a struct,
+// or prefixes the other errors with the map key or the field name
+func nestedError(err error, key string, source string, name string) error {
+	if _, ok := err.(*ConversionError); ok {
+		return withKey(err, key, source)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// toMap returns the items of the map types the config sources produce with string keys
+func toMap(val interface{}) (map[string]interface{}, bool) {
+	switch val := val.(type) {
+	case map[string]interface{}:
+		return val, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, value := range val {
+			m[convertToString(k)] = value
+		}
+		return m, true
+	}
+	return nil, false
+}
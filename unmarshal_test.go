@@ -0,0 +1,177 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDatabaseConfig struct {
+	Host     string `gonfig:"host"`
+	Port     int    `gonfig:"port"`
+	Password string `gonfig:"password"`
+}
+
+type testServerConfig struct {
+	Name string
+	Port uint16 `gonfig:"port"`
+}
+
+type testConfig struct {
+	Name     string              `gonfig:"name"`
+	Debug    bool                `gonfig:"debug"`
+	Ratio    float64             `gonfig:"ratio"`
+	Database testDatabaseConfig  `gonfig:"db"`
+	Cache    *testDatabaseConfig `gonfig:"cache"`
+	Servers  []testServerConfig  `gonfig:"servers"`
+	Tags     []string            `gonfig:"tags"`
+	Ports    []int               `gonfig:"ports"`
+	Labels   map[string]string   `gonfig:"labels"`
+	Limits   map[string]int      `gonfig:"limits"`
+	Ignored  string              `gonfig:"-"`
+	Timeout  int                 `gonfig:"timeout"`
+}
+
+func Test_Unmarshal(t *testing.T) {
+	var c Configuration
+	mockFile("{\"name\":\"svc\", \"debug\":1, \"ratio\":0.5, \"db\":{\"host\":\"localhost\",\"port\":5432}, \"servers\":[{\"name\":\"a\",\"port\":80},{\"name\":\"b\",\"port\":443}], \"tags\":[\"x\",\"y\"], \"ports\":[1,2], \"labels\":{\"team\":\"core\"}, \"limits\":{\"cpu\":2}, \"Ignored\":\"nope\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeEnv,
+	})
+	os.Setenv("DB_PASSWORD", "secret")
+	defer os.Unsetenv("DB_PASSWORD")
+	os.Setenv("SERVERS_1_PORT", "8443")
+	defer os.Unsetenv("SERVERS_1_PORT")
+	cfg := testConfig{Timeout: 30}
+	err := c.Unmarshal(&cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, "svc", cfg.Name)
+	assert.Equal(t, true, cfg.Debug)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, testDatabaseConfig{Host: "localhost", Port: 5432, Password: "secret"}, cfg.Database)
+	assert.Nil(t, cfg.Cache) // None of the keys of cache are found
+	assert.Equal(t, []testServerConfig{{Name: "a", Port: 80}, {Name: "b", Port: 8443}}, cfg.Servers)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	assert.Equal(t, []int{1, 2}, cfg.Ports)
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	assert.Equal(t, map[string]int{"cpu": 2}, cfg.Limits)
+	assert.Equal(t, "", cfg.Ignored)
+	assert.Equal(t, 30, cfg.Timeout) // Not found keys keep their preset values
+}
+
+func Test_Unmarshal_Pointer(t *testing.T) {
+	var c Configuration
+	mockFile("cache:\n  host: redis\n  port: 6379", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: "testing.yaml",
+	})
+	var cfg testConfig
+	err := c.Unmarshal(&cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, &testDatabaseConfig{Host: "redis", Port: 6379}, cfg.Cache)
+}
+
+func Test_Unmarshal_Errors(t *testing.T) {
+	var c Configuration
+	mockFile("{\"debug\":\"maybe\", \"db\":{\"port\":\"abc\"}, \"servers\":[{\"port\":70000}], \"ports\":\"80\"}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	var cfg testConfig
+	err := c.Unmarshal(&cfg)
+	var unmarshalErr *UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 4, len(unmarshalErr.Fields))
+	assert.Equal(t, "testConfig.Debug", unmarshalErr.Fields[0].Field)
+	assert.Equal(t, "debug", unmarshalErr.Fields[0].Key)
+	assert.Equal(t, "db.port", unmarshalErr.Fields[1].Key)
+	assert.Equal(t, "testConfig.Servers[0].Port", unmarshalErr.Fields[2].Field)
+	assert.Equal(t, "servers[0].port", unmarshalErr.Fields[2].Key)
+	assert.Equal(t, "ports", unmarshalErr.Fields[3].Key)
+	// Invalid targets
+	assert.EqualError(t, c.Unmarshal(cfg), "The target must be a non-nil pointer to a struct")
+	assert.EqualError(t, c.Unmarshal(nil), "The target must be a non-nil pointer to a struct")
+}
+
+func Test_Unmarshal_Arrays(t *testing.T) {
+	var c Configuration
+	mockFile("{\"ports\":[80,\"abc\",443], \"limits\":{\"cpu\":\"x\"}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	var cfg struct {
+		Ports  []int
+		Limits map[string]int
+	}
+	// The items that cannot be converted are skipped like GetIntArray does
+	err := c.Unmarshal(&cfg)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	var unmarshalErr *UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, 1, len(unmarshalErr.Fields))
+	// The fields of anonymous structs have no root name
+	assert.Equal(t, "Limits", unmarshalErr.Fields[0].Field)
+	var convErr *ConversionError
+	assert.True(t, errors.As(unmarshalErr.Fields[0].Err, &convErr))
+	assert.Equal(t, "limits.cpu", convErr.Key)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+	// or the first of them is reported with its index if StrictArrays is set
+	c.StrictArrays = true
+	err = c.Unmarshal(&cfg)
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, "Ports", unmarshalErr.Fields[0].Field)
+	assert.True(t, errors.As(unmarshalErr.Fields[0].Err, &convErr))
+	assert.Equal(t, "ports[1]", convErr.Key)
+	assert.Equal(t, "abc", convErr.Value)
+	assert.Equal(t, "json:testing.json", convErr.Source)
+	_, err = Get[[]int](c, "ports")
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "ports[1]", convErr.Key)
+}
+
+func Test_Bind(t *testing.T) {
+	var c Configuration
+	mockFile("{\"db\":{\"host\":\"localhost\",\"port\":5432}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	var db testDatabaseConfig
+	err := c.Bind("db", &db)
+	assert.Nil(t, err)
+	assert.Equal(t, testDatabaseConfig{Host: "localhost", Port: 5432}, db)
+}
+
+type testNode struct {
+	Name string    `gonfig:"name"`
+	Next *testNode `gonfig:"next"`
+}
+
+func Test_Unmarshal_SelfReferential(t *testing.T) {
+	var c Configuration
+	mockFile("{\"name\":\"first\", \"next\":{\"name\":\"second\", \"next\":{\"name\":\"third\"}}}", nil)
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: "testing.json",
+	})
+	var node testNode
+	assert.Nil(t, c.Unmarshal(&node))
+	assert.Equal(t, "first", node.Name)
+	assert.Equal(t, "second", node.Next.Name)
+	assert.Equal(t, "third", node.Next.Next.Name)
+	assert.Nil(t, node.Next.Next.Next)
+	// Without any keys the recursion stops at the first repeated type
+	var empty Configuration
+	var root testNode
+	assert.Nil(t, empty.Unmarshal(&root))
+	assert.Nil(t, root.Next)
+}
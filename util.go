@@ -2,35 +2,91 @@ package gonfig
 
 import (
 	"encoding/json"
+	"io"
+	"io/fs"
 	"os"
 
+	"github.com/BurntSushi/toml"
 	yaml "gopkg.in/yaml.v2"
 )
 
 var myReadFile = os.ReadFile
 
-func readJSON(filePath string) (map[string]interface{}, error) {
+func readFile(filePath string, decode Decoder) (map[string]interface{}, error) {
 	readBytes, err := myReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
+	return decode(readBytes)
+}
+
+// readSource reads the content of a file based ConfigSource from its Data, Reader, FS or FilePath in this order
+func readSource(s ConfigSource) ([]byte, error) {
+	switch {
+	case s.Data != nil:
+		return s.Data, nil
+	case s.Reader != nil:
+		return io.ReadAll(s.Reader)
+	case s.FS != nil:
+		return fs.ReadFile(s.FS, s.FilePath)
+	}
+	return myReadFile(s.FilePath)
+}
+
+func readJSON(filePath string) (map[string]interface{}, error) {
+	return readFile(filePath, decodeJSON)
+}
+
+func readYaml(filePath string) (map[string]interface{}, error) {
+	return readFile(filePath, decodeYaml)
+}
+
+func decodeJSON(data []byte) (map[string]interface{}, error) {
 	var output map[string]interface{}
-	err = json.Unmarshal(readBytes, &output)
+	err := json.Unmarshal(data, &output)
 	if err != nil {
 		return nil, err
 	}
 	return output, nil
 }
 
-func readYaml(filePath string) (map[string]interface{}, error) {
-	readBytes, err := myReadFile(filePath)
+func decodeYaml(data []byte) (map[string]interface{}, error) {
+	var output map[string]interface{}
+	err := yaml.Unmarshal(data, &output)
 	if err != nil {
 		return nil, err
 	}
+	return output, nil
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, error) {
 	var output map[string]interface{}
-	err = yaml.Unmarshal(readBytes, &output)
+	err := toml.Unmarshal(data, &output)
 	if err != nil {
 		return nil, err
 	}
-	return output, nil
+	return normalizeTOML(output).(map[string]interface{}), nil
+}
+
+// normalizeTOML converts arrays of tables, which are decoded as []map[string]interface{}, to []interface{} so that they can be treated like the other arrays
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = normalizeTOML(item)
+		}
+		return arr
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	}
+	return value
 }
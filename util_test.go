@@ -2,7 +2,10 @@ package gonfig
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,3 +75,69 @@ func Test_readYaml_UnmarshalError(t *testing.T) {
 	assert.EqualError(t, err, "yaml: did not find expected key")
 	assert.Nil(t, result)
 }
+
+func Test_decodeTOML_Success(t *testing.T) {
+	result, err := decodeTOML([]byte("key1 = \"value1\"\nintkey3 = 3\ncreated = 1979-05-27T07:32:00Z\nports = [80, 443]\n\n[database]\nhost = \"localhost\"\n\n[[servers]]\nname = \"a\"\n\n[[servers]]\nname = \"b\"\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", result["key1"])
+	assert.Equal(t, int64(3), result["intkey3"])
+	assert.Equal(t, time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), result["created"])
+	assert.Equal(t, []interface{}{int64(80), int64(443)}, result["ports"])
+	assert.Equal(t, map[string]interface{}{"host": "localhost"}, result["database"])
+	// Arrays of tables are converted to []interface{} like the other arrays
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}, result["servers"])
+	val, found := lookupPath(result, "servers[1].name")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "b", val)
+}
+
+func Test_decodeTOML_UnmarshalError(t *testing.T) {
+	result, err := decodeTOML([]byte("key1 = \"value1\nkey2 = 2"))
+	assert.NotNil(t, err)
+	assert.Nil(t, result)
+}
+
+func Test_readSource(t *testing.T) {
+	mockFile("from disk", nil)
+	data, err := readSource(ConfigSource{FilePath: "testing.json"})
+	assert.Nil(t, err)
+	assert.Equal(t, "from disk", string(data))
+	data, err = readSource(ConfigSource{FilePath: "testing.json", Data: []byte("from bytes")})
+	assert.Nil(t, err)
+	assert.Equal(t, "from bytes", string(data))
+	data, err = readSource(ConfigSource{Reader: strings.NewReader("from reader")})
+	assert.Nil(t, err)
+	assert.Equal(t, "from reader", string(data))
+	fsys := fstest.MapFS{"config/defaults.json": &fstest.MapFile{Data: []byte("from fs")}}
+	data, err = readSource(ConfigSource{FS: fsys, FilePath: "config/defaults.json"})
+	assert.Nil(t, err)
+	assert.Equal(t, "from fs", string(data))
+	_, err = readSource(ConfigSource{FS: fsys, FilePath: "config/missing.json"})
+	assert.NotNil(t, err)
+}
+
+func Test_AddConfigSource_Content(t *testing.T) {
+	var c Configuration
+	mockFile("", errors.New("File reading error")) // None of the sources below should touch the disk
+	fsys := fstest.MapFS{"defaults.yaml": &fstest.MapFile{Data: []byte("key1: value1\nkey2: value2\nkey3: value3")}}
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FS:       fsys,
+		FilePath: "defaults.yaml",
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeJSON,
+		Data: []byte("{\"key2\":\"value2_2\"}"),
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type:   SourceTypeTOML,
+		Reader: strings.NewReader("key3 = \"value3_3\""),
+	})
+	assert.Equal(t, false, c.HasError)
+	assert.Equal(t, "yaml:defaults.yaml", c.sources[0].provider.Name())
+	assert.Equal(t, "json:bytes", c.sources[1].provider.Name())
+	assert.Equal(t, "toml:reader", c.sources[2].provider.Name())
+	assert.Equal(t, "value1", c.GetStringOrDefault("key1", ""))
+	assert.Equal(t, "value2_2", c.GetStringOrDefault("key2", ""))
+	assert.Equal(t, "value3_3", c.GetStringOrDefault("key3", ""))
+}
@@ -0,0 +1,302 @@
+package gonfig
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultWatchDebounce = 100 * time.Millisecond
+)
+
+// WatchOptions configures how a Watcher detects the changes of the files
+type WatchOptions struct {
+	// Interval is how often the files are checked for changes. Defaults to 1 second
+	Interval time.Duration
+	// Debounce is how long the files must stay unchanged after a change before they are reloaded,
+	// so that a burst of writes causes a single reload. Defaults to 100 milliseconds
+	Debounce time.Duration
+}
+
+// Watcher reloads the file based sources of a Configuration when their files change.
+// The files are polled with os.Stat, which follows symlinks, so atomic symlink swaps like Kubernetes ConfigMap updates are detected too.
+type Watcher struct {
+	store    *Store
+	watched  map[int]string    // paths of the watched sources by their indices, never modified after Watch returns
+	files    map[int]fileState // only accessed by the watching goroutine
+	reload   sync.Mutex        // serializes the reloads, so that the updates and their notifications don't interleave
+	options  WatchOptions
+	errors   chan error
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	listenersLock sync.Mutex
+	subscribers   []chan ChangeEvent
+	callbacks     []keyCallback
+}
+
+// ChangeEvent describes the keys that are changed by reloading a config source
+type ChangeEvent struct {
+	// Source is the name of the reloaded source, e.g. "json:/etc/app/config.json"
+	Source string
+	// Keys are the sorted dotted keys whose values are added, changed or removed in the source
+	Keys []string
+}
+
+type keyCallback struct {
+	key      string
+	callback func(oldValue, newValue interface{})
+}
+
+// fileState is what's compared to detect the change of a file
+type fileState struct {
+	info os.FileInfo
+}
+
+func statFile(path string) fileState {
+	info, _ := os.Stat(path) // A missing file is a state too, info is nil then
+	return fileState{info: info}
+}
+
+func (s fileState) changed(other fileState) bool {
+	if s.info == nil || other.info == nil {
+		return (s.info == nil) != (other.info == nil)
+	}
+	return !os.SameFile(s.info, other.info) || !s.info.ModTime().Equal(other.info.ModTime()) || s.info.Size() != other.info.Size()
+}
+
+// Watch starts watching the files behind the file based sources of the Configuration, i.e. the ones that are read from FilePath on the disk.
+// When a file changes, it's parsed again and the new values are swapped in atomically. Use Config to get the latest Configuration.
+// If a file cannot be read or parsed, the last good values of it are kept and a *LoadError is sent to the Errors channel.
+// Call Close to stop watching.
+func (c Configuration) Watch(options WatchOptions) *Watcher {
+	return NewStore(c).Watch(options)
+}
+
+// Watch starts watching the files behind the file based sources of the Configuration in the Store, and swaps the reloaded values into the Store.
+// Sources that are added to the Store later are not watched. See Configuration.Watch.
+func (s *Store) Watch(options WatchOptions) *Watcher {
+	if options.Interval <= 0 {
+		options.Interval = defaultWatchInterval
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = defaultWatchDebounce
+	}
+	w := &Watcher{
+		store:   s,
+		watched: make(map[int]string),
+		files:   make(map[int]fileState),
+		options: options,
+		errors:  make(chan error, 16),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i, loadedSource := range s.Load().sources {
+		if isWatchable(loadedSource.source) {
+			w.watched[i] = loadedSource.source.FilePath
+			w.files[i] = statFile(loadedSource.source.FilePath)
+		}
+	}
+	go w.run()
+	return w
+}
+
+// isWatchable reports whether the source is read from a file on the disk
+func isWatchable(s ConfigSource) bool {
+	return s.FilePath != "" && s.FS == nil && s.Data == nil && s.Reader == nil
+}
+
+// Config returns the latest Configuration. It's safe to call from multiple goroutines.
+func (w *Watcher) Config() Configuration {
+	return w.store.Load()
+}
+
+// Store returns the Store that the reloaded values are swapped into
+func (w *Watcher) Store() *Store {
+	return w.store
+}
+
+// Errors returns the channel that the reload errors are sent to.
+// Errors are dropped if the channel is full, so it should be drained by the caller.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Subscribe returns a channel that receives a ChangeEvent for every reloaded source whose values have changed.
+// Events are sent by the goroutine that reloads, like the callbacks of OnChange, and they're dropped if the channel is full,
+// so it should be drained by the caller. The channel is closed by Close.
+func (w *Watcher) Subscribe() <-chan ChangeEvent {
+	w.listenersLock.Lock()
+	defer w.listenersLock.Unlock()
+	ch := make(chan ChangeEvent, 16)
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+// OnChange registers a callback that's called with the old and the new value of the key when a reload changes its merged value.
+// The values are nil if the key is not found. Callbacks are called in the order they're registered, on the goroutine that reloads:
+// the watching goroutine for the file changes, or the caller of Reload. The reloads wait for the callbacks to return,
+// so a callback must not call Reload, or block on something that waits for a reload.
+func (w *Watcher) OnChange(key string, callback func(oldValue, newValue interface{})) {
+	w.listenersLock.Lock()
+	defer w.listenersLock.Unlock()
+	w.callbacks = append(w.callbacks, keyCallback{key: key, callback: callback})
+}
+
+// Close stops watching the files
+func (w *Watcher) Close() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		w.listenersLock.Lock()
+		defer w.listenersLock.Unlock()
+		for _, ch := range w.subscribers {
+			close(ch)
+		}
+		w.subscribers = nil
+	})
+	<-w.done
+}
+
+// Reload reads all the watched files again regardless of their changes, e.g. on SIGHUP.
+// Returns a *LoadError with the errors of the files that could not be reloaded, whose last good values are kept.
+func (w *Watcher) Reload() error {
+	all := make(map[int]bool, len(w.watched))
+	for i := range w.watched {
+		all[i] = true
+	}
+	return w.reloadSources(all)
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+	dirty := make(map[int]bool)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			for i, state := range w.files {
+				newState := statFile(w.watched[i])
+				if state.changed(newState) {
+					w.files[i] = newState
+					dirty[i] = true
+					debounce = time.After(w.options.Debounce) // Every change restarts the debounce period
+				}
+			}
+		case <-debounce:
+			debounce = nil
+			if err := w.reloadSources(dirty); err != nil {
+				select {
+				case w.errors <- err:
+				default:
+				}
+			}
+			dirty = make(map[int]bool)
+		}
+	}
+}
+
+// reloadSources loads the given sources again, swaps the new Configuration in and notifies the listeners.
+// Sources that fail to load keep their last good values. Sources that are no longer at their index in the Store,
+// e.g. because the Store is updated to another Configuration, are skipped.
+func (w *Watcher) reloadSources(indices map[int]bool) error {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+	var previous, next Configuration
+	var errs []SourceError
+	var events []ChangeEvent
+	w.store.Update(func(current Configuration) Configuration {
+		previous, next = current, current
+		next.sources = append([]loadedSource(nil), current.sources...)
+		for i := range indices {
+			if i >= len(next.sources) || !isWatchable(next.sources[i].source) || next.sources[i].source.FilePath != w.watched[i] {
+				continue
+			}
+			loaded := loadedSource{source: next.sources[i].source}
+			loaded.provider, loaded.err = newSource(loaded.source)
+			if loaded.err == nil {
+				loaded.err = loaded.provider.Load()
+			}
+			if loaded.err != nil {
+				errs = append(errs, newSourceError(loaded))
+				continue
+			}
+			if keys := changedKeys(next.sources[i].provider, loaded.provider); len(keys) > 0 {
+				events = append(events, ChangeEvent{Source: loaded.provider.Name(), Keys: keys})
+			}
+			next.sources[i] = loaded
+		}
+		next.HasError = len(next.setErrors) > 0
+		for _, loadedSource := range next.sources {
+			if loadedSource.err != nil {
+				next.HasError = true
+			}
+		}
+		return next
+	})
+	w.notify(previous, next, events)
+	if len(errs) > 0 {
+		return &LoadError{Sources: errs}
+	}
+	return nil
+}
+
+// notify sends the change events to the subscribers and calls the callbacks of the changed keys
+func (w *Watcher) notify(previous Configuration, next Configuration, events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Source < events[j].Source })
+	w.listenersLock.Lock()
+	for _, event := range events { // Sent while holding the lock, so that Close cannot close the channels meanwhile
+		for _, ch := range w.subscribers {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+	callbacks := append([]keyCallback(nil), w.callbacks...)
+	w.listenersLock.Unlock()
+	for _, cb := range callbacks {
+		oldValue, _ := previous.findKey(cb.key)
+		newValue, _ := next.findKey(cb.key)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			cb.callback(oldValue, newValue)
+		}
+	}
+}
+
+// changedKeys returns the sorted dotted keys whose values differ between two versions of a source
+func changedKeys(previous Source, next Source) []string {
+	oldItems := make(map[string]interface{})
+	newItems := make(map[string]interface{})
+	if enumerable, ok := previous.(Enumerable); ok {
+		flatten(enumerable.Settings(), "", oldItems)
+	}
+	if enumerable, ok := next.(Enumerable); ok {
+		flatten(enumerable.Settings(), "", newItems)
+	}
+	var keys []string
+	for key, newValue := range newItems {
+		if oldValue, found := oldItems[key]; !found || !reflect.DeepEqual(oldValue, newValue) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range oldItems {
+		if _, found := newItems[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,202 @@
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testWatchOptions = WatchOptions{Interval: 5 * time.Millisecond, Debounce: 20 * time.Millisecond}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.Nil(t, err)
+}
+
+func Test_Watch_Reload(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, "{\"key1\":\"value1\"}")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: path,
+	})
+	c = c.SetDefault("key2", "default")
+	w := c.Watch(testWatchOptions)
+	defer w.Close()
+	assert.Equal(t, "value1", w.Config().GetStringOrDefault("key1", ""))
+	writeTestFile(t, path, "{\"key1\":\"value1_2\"}")
+	assert.Eventually(t, func() bool {
+		return w.Config().GetStringOrDefault("key1", "") == "value1_2"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "default", w.Config().GetStringOrDefault("key2", ""))
+	// The original Configuration is not changed
+	assert.Equal(t, "value1", c.GetStringOrDefault("key1", ""))
+}
+
+func Test_Watch_ParseError(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeTestFile(t, path, "key1: value1")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeYaml,
+		FilePath: path,
+	})
+	w := c.Watch(testWatchOptions)
+	defer w.Close()
+	writeTestFile(t, path, "key1: \"value1_2\tkey2: value2")
+	select {
+	case err := <-w.Errors():
+		assert.Contains(t, err.Error(), path)
+	case <-time.After(time.Second):
+		t.Fatal("The parse error is not reported")
+	}
+	// The last good values are kept
+	assert.Equal(t, "value1", w.Config().GetStringOrDefault("key1", ""))
+	assert.Equal(t, false, w.Config().HasError)
+	// And the file is reloaded once it's fixed
+	writeTestFile(t, path, "key1: value1_3")
+	assert.Eventually(t, func() bool {
+		return w.Config().GetStringOrDefault("key1", "") == "value1_3"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_Watch_SymlinkSwap(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	// The layout Kubernetes uses for ConfigMap volumes: config.json -> ..data/config.json, ..data -> ..v1
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "..v1"), 0755))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "..v2"), 0755))
+	writeTestFile(t, filepath.Join(dir, "..v1", "config.json"), "{\"key1\":\"value1\"}")
+	writeTestFile(t, filepath.Join(dir, "..v2", "config.json"), "{\"key1\":\"value2\"}")
+	assert.Nil(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "config.json"), filepath.Join(dir, "config.json")))
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: filepath.Join(dir, "config.json"),
+	})
+	w := c.Watch(testWatchOptions)
+	defer w.Close()
+	assert.Equal(t, "value1", w.Config().GetStringOrDefault("key1", ""))
+	// Swap the ..data symlink atomically
+	assert.Nil(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+	assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	assert.Eventually(t, func() bool {
+		return w.Config().GetStringOrDefault("key1", "") == "value2"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_Watcher_Reload(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, "{\"key1\":\"value1\"}")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: path,
+	})
+	c = c.AddConfigSource(ConfigSource{
+		Type: SourceTypeJSON,
+		Data: []byte("{\"key2\":\"value2\"}"),
+	})
+	w := c.Watch(WatchOptions{Interval: time.Hour})
+	defer w.Close()
+	writeTestFile(t, path, "{\"key1\":\"value1_2\"}")
+	assert.Nil(t, w.Reload())
+	assert.Equal(t, "value1_2", w.Config().GetStringOrDefault("key1", ""))
+	assert.Equal(t, "value2", w.Config().GetStringOrDefault("key2", ""))
+	assert.Nil(t, os.Remove(path))
+	assert.NotNil(t, w.Reload())
+	assert.Equal(t, "value1_2", w.Config().GetStringOrDefault("key1", ""))
+}
+
+func Test_Watcher_StoreUpdated(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, "{\"key1\":\"value1\"}")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: path,
+	})
+	store := NewStore(c)
+	w := store.Watch(WatchOptions{Interval: 5 * time.Millisecond, Debounce: time.Millisecond})
+	defer w.Close()
+	events := 0
+	w.OnChange("key1", func(oldValue, newValue interface{}) {
+		events++
+	})
+	store.Update(func(Configuration) Configuration {
+		return Configuration{}
+	})
+	writeTestFile(t, path, "{\"key1\":\"value2\"}")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, w.Reload())
+		}()
+	}
+	wg.Wait()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, events)
+	_, err := w.Config().GetString("key1")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func Test_Watcher_Subscribe_OnChange(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, "{\"pool\":{\"size\":10}, \"log\":{\"level\":\"info\"}, \"name\":\"svc\"}")
+	var c Configuration
+	c = c.AddConfigSource(ConfigSource{
+		Type:     SourceTypeJSON,
+		FilePath: path,
+	})
+	c = c.Set("log.level", "debug")
+	w := c.Watch(WatchOptions{Interval: time.Hour})
+	events := w.Subscribe()
+	var poolChanges [][]interface{}
+	w.OnChange("pool.size", func(oldValue, newValue interface{}) {
+		poolChanges = append(poolChanges, []interface{}{oldValue, newValue})
+	})
+	logChanged := false
+	w.OnChange("log.level", func(oldValue, newValue interface{}) {
+		logChanged = true
+	})
+	writeTestFile(t, path, "{\"pool\":{\"size\":20}, \"log\":{\"level\":\"warn\"}, \"timeout\":5}")
+	assert.Nil(t, w.Reload())
+	event := <-events
+	assert.Equal(t, "json:"+path, event.Source)
+	assert.Equal(t, []string{"log.level", "name", "pool.size", "timeout"}, event.Keys)
+	assert.Equal(t, [][]interface{}{{10.0, 20.0}}, poolChanges)
+	// log.level is overridden, so its merged value doesn't change
+	assert.Equal(t, false, logChanged)
+	// Reloading without changes doesn't send events
+	assert.Nil(t, w.Reload())
+	assert.Equal(t, 0, len(events))
+	w.Close()
+	_, open := <-events
+	assert.Equal(t, false, open)
+}
+
+func Test_changedKeys(t *testing.T) {
+	previous := NewMapSource(map[string]interface{}{"a": 1, "b": []interface{}{1, 2}, "c": map[string]interface{}{"d": 1}})
+	next := NewMapSource(map[string]interface{}{"a": 1, "b": []interface{}{1, 3}, "c": map[string]interface{}{"e": 1}})
+	assert.Equal(t, []string{"b[1]", "c.d", "c.e"}, changedKeys(previous, next))
+	assert.Equal(t, []string{"a", "b[0]", "b[1]", "c.e"}, changedKeys(nil, next))
+	assert.Nil(t, changedKeys(next, next))
+}